@@ -0,0 +1,196 @@
+package gosc
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/netip"
+	"path"
+	"sync"
+)
+
+// ErrForbidden is reported to Metrics for packages dropped by an ACL.
+var ErrForbidden = errors.New("forbidden")
+
+// ACLOptions is the access control list of an ACL. Networks are given in CIDR
+// notation, e.g. "192.168.1.0/24", or as single IP addresses.
+type ACLOptions struct {
+	// Allow is the networks allowed to send packages, all sources are allowed
+	// if empty.
+	Allow []string
+	// Deny is the networks not allowed to send packages, even if allowed by
+	// Allow or Rules.
+	Deny []string
+	// Rules restricts the messages on some addresses further. The first rule
+	// matching the address of a message decides the networks allowed to send
+	// it.
+	Rules []ACLRule
+	// Policy is applied to packages that are not allowed, defaults to
+	// DropSilently. DropAndReply replies with "forbidden".
+	Policy DropPolicy
+	// OnDeny is called for every package that is not allowed, if not nil.
+	OnDeny func(src net.Addr, pkg Package)
+}
+
+// ACLRule allows the messages on the addresses matching Pattern only from the
+// networks in Allow.
+type ACLRule struct {
+	// Pattern is matched with the address of messages using path.Match, e.g.
+	// "/scene/*".
+	Pattern string
+	Allow   []string
+}
+
+type aclRule struct {
+	pattern string
+	allow   []netip.Prefix
+}
+
+// ACL is a PackageHandler passing only the packages allowed by its access
+// control list to the next handler. Bundles are allowed if all their messages
+// are. Sources without an IP address, e.g. unix sockets, are only allowed if
+// no networks are required. Created by NewACL.
+type ACL struct {
+	next  PackageHandler
+	mu    sync.RWMutex
+	opts  ACLOptions
+	allow []netip.Prefix
+	deny  []netip.Prefix
+	rules []aclRule
+}
+
+// NewACL returns an ACL passing the packages allowed by opts to next. It
+// returns error if a network or pattern is malformed.
+func NewACL(next PackageHandler, opts ACLOptions) (*ACL, error) {
+	acl := &ACL{next: next}
+	if err := acl.Update(opts); err != nil {
+		return nil, err
+	}
+	return acl, nil
+}
+
+// Update replaces the access control list, it can be called while the ACL is
+// handling packages. The previous list is kept if opts are malformed.
+func (a *ACL) Update(opts ACLOptions) error {
+	allow, err := parsePrefixes(opts.Allow)
+	if err != nil {
+		return err
+	}
+	deny, err := parsePrefixes(opts.Deny)
+	if err != nil {
+		return err
+	}
+	rules := make([]aclRule, 0, len(opts.Rules))
+	for _, r := range opts.Rules {
+		if _, err := path.Match(r.Pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", r.Pattern, err)
+		}
+		prefixes, err := parsePrefixes(r.Allow)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, aclRule{pattern: r.Pattern, allow: prefixes})
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.opts, a.allow, a.deny, a.rules = opts, allow, deny, rules
+	return nil
+}
+
+// HandlePackage passes pkg to the next handler if it is allowed, otherwise the
+// package is dropped according to the policy.
+func (a *ACL) HandlePackage(w *ResponseWriter, pkg Package) {
+	var src net.Addr
+	if w != nil {
+		src = w.src
+	}
+	a.mu.RLock()
+	opts := a.opts
+	allowed := a.allowed(sourceIP(src), pkg)
+	a.mu.RUnlock()
+	if allowed {
+		a.next.HandlePackage(w, pkg)
+		return
+	}
+
+	if opts.OnDeny != nil {
+		opts.OnDeny(src, pkg)
+	}
+	drop(w, pkg, ErrForbidden, slog.LevelInfo, opts.Policy)
+}
+
+// allowed returns if ip may send pkg. a.mu must be held.
+func (a *ACL) allowed(ip netip.Addr, pkg Package) bool {
+	if containsIP(a.deny, ip) {
+		return false
+	}
+	if len(a.allow) > 0 && !containsIP(a.allow, ip) {
+		return false
+	}
+	switch x := pkg.(type) {
+	case *Message:
+		for _, r := range a.rules {
+			if ok, _ := path.Match(r.pattern, x.Address); ok {
+				return containsIP(r.allow, ip)
+			}
+		}
+	case *Bundle:
+		for _, msg := range x.Messages {
+			if !a.allowed(ip, msg) {
+				return false
+			}
+		}
+		for _, b := range x.Bundles {
+			if !a.allowed(ip, b) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func parsePrefixes(networks []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(networks))
+	for _, n := range networks {
+		if prefix, err := netip.ParsePrefix(n); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(n)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q", n)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+func containsIP(prefixes []netip.Prefix, ip netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceIP returns the IP address of src, or the zero Addr if it has none.
+func sourceIP(src net.Addr) netip.Addr {
+	switch x := src.(type) {
+	case *net.UDPAddr:
+		return x.AddrPort().Addr().Unmap()
+	case *net.TCPAddr:
+		return x.AddrPort().Addr().Unmap()
+	}
+	if src == nil {
+		return netip.Addr{}
+	}
+	addr, err := netip.ParseAddr(SourceKey(src, nil))
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
@@ -0,0 +1,107 @@
+package gosc
+
+import (
+	"net"
+	"testing"
+)
+
+func TestNewACL(t *testing.T) {
+	next := HandlerFunc(func(_ *ResponseWriter, _ Package) {})
+	t.Run("valid", func(t *testing.T) {
+		_, err := NewACL(next, ACLOptions{
+			Allow: []string{"192.168.1.0/24", "10.0.0.1", "::1"},
+			Rules: []ACLRule{{Pattern: "/scene/*", Allow: []string{"192.168.1.0/28"}}},
+		})
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+	t.Run("invalidNetwork", func(t *testing.T) {
+		if _, err := NewACL(next, ACLOptions{Deny: []string{"10.0.0.0/33"}}); err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("invalidPattern", func(t *testing.T) {
+		if _, err := NewACL(next, ACLOptions{Rules: []ACLRule{{Pattern: "/scene/["}}}); err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+}
+
+func TestACL_HandlePackage(t *testing.T) {
+	foh := &net.UDPAddr{IP: net.IPv4(192, 168, 1, 10), Port: 9000}
+	stage := &net.UDPAddr{IP: net.IPv4(192, 168, 2, 10), Port: 9000}
+	outside := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 9000}
+	var handled []Package
+	acl, err := NewACL(HandlerFunc(func(_ *ResponseWriter, pkg Package) {
+		handled = append(handled, pkg)
+	}), ACLOptions{
+		Allow: []string{"192.168.0.0/16"},
+		Deny:  []string{"192.168.2.99"},
+		Rules: []ACLRule{{Pattern: "/scene/*", Allow: []string{"192.168.1.0/24"}}},
+	})
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	tests := []struct {
+		name    string
+		src     net.Addr
+		pkg     Package
+		allowed bool
+	}{
+		{"allowed", stage, &Message{Address: "/fader/1"}, true},
+		{"notAllowed", outside, &Message{Address: "/fader/1"}, false},
+		{"denied", &net.UDPAddr{IP: net.IPv4(192, 168, 2, 99)}, &Message{Address: "/fader/1"}, false},
+		{"ruleAllowed", foh, &Message{Address: "/scene/recall"}, true},
+		{"ruleNotAllowed", stage, &Message{Address: "/scene/recall"}, false},
+		{"mappedIPv6", &net.UDPAddr{IP: net.ParseIP("::ffff:192.168.1.10")}, &Message{Address: "/scene/recall"}, true},
+		{"noIP", &net.UnixAddr{Name: "/run/osc.sock", Net: "unixgram"}, &Message{Address: "/fader/1"}, false},
+		{"bundle", foh, &Bundle{Bundles: []*Bundle{{Messages: []*Message{{Address: "/scene/recall"}}}}}, true},
+		{"bundleNotAllowed", stage, &Bundle{Bundles: []*Bundle{{Messages: []*Message{{Address: "/scene/recall"}}}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled = nil
+			acl.HandlePackage(NewResponseWriter(&testTransport{}, tt.src), tt.pkg)
+			if (len(handled) == 1) != tt.allowed {
+				t.Errorf("expected allowed %v but got handled: %v", tt.allowed, handled)
+			}
+		})
+	}
+}
+
+func TestACL_Update(t *testing.T) {
+	src := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 9000}
+	handled := 0
+	var denied []net.Addr
+	acl, _ := NewACL(HandlerFunc(func(_ *ResponseWriter, _ Package) { handled++ }), ACLOptions{})
+	trans := &testTransport{}
+	acl.HandlePackage(NewResponseWriter(trans, src), &Message{Address: "/test"})
+
+	err := acl.Update(ACLOptions{
+		Deny:   []string{"10.0.0.0/8"},
+		Policy: DropAndReply,
+		OnDeny: func(src net.Addr, _ Package) { denied = append(denied, src) },
+	})
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	acl.HandlePackage(NewResponseWriter(trans, src), &Message{Address: "/test"})
+	if handled != 1 {
+		t.Errorf("expected 1 package handled but got: %d", handled)
+	}
+	if len(denied) != 1 || denied[0] != src {
+		t.Errorf("expected OnDeny to be called with source but got: %v", denied)
+	}
+	if len(trans.sent) != 1 || trans.sent[0].(*Message).Arguments[1] != "forbidden" {
+		t.Errorf("expected forbidden reply but got: %v", trans.sent)
+	}
+
+	if err := acl.Update(ACLOptions{Allow: []string{"invalid"}}); err == nil {
+		t.Error("expected error but none given")
+	}
+	acl.HandlePackage(NewResponseWriter(trans, src), &Message{Address: "/test"})
+	if handled != 1 {
+		t.Errorf("expected previous list to be kept but got %d packages handled", handled)
+	}
+}
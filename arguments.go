@@ -0,0 +1,71 @@
+package gosc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Int32 returns the argument at index i as an int32. An error is returned if
+// the index is out of range or the argument is of another type.
+func (m *Message) Int32(i int) (int32, error) {
+	return argument[int32](m, i)
+}
+
+// Float32 returns the argument at index i as a float32. An error is returned
+// if the index is out of range or the argument is of another type.
+func (m *Message) Float32(i int) (float32, error) {
+	return argument[float32](m, i)
+}
+
+// StringArg returns the argument at index i as a string. An error is returned
+// if the index is out of range or the argument is of another type. It is not
+// named String since that would collide with the fmt.Stringer implementation.
+func (m *Message) StringArg(i int) (string, error) {
+	return argument[string](m, i)
+}
+
+// Blob returns the argument at index i as a blob. An error is returned if the
+// index is out of range or the argument is of another type.
+func (m *Message) Blob(i int) ([]byte, error) {
+	return argument[[]byte](m, i)
+}
+
+// Bool returns the argument at index i as a bool. An error is returned if the
+// index is out of range or the argument is of another type.
+func (m *Message) Bool(i int) (bool, error) {
+	return argument[bool](m, i)
+}
+
+// Timetag returns the argument at index i as a Timetag. An error is returned
+// if the index is out of range or the argument is of another type.
+func (m *Message) Timetag(i int) (Timetag, error) {
+	return argument[Timetag](m, i)
+}
+
+// TypeTags returns the OSC type tag string of the arguments, including the
+// leading ',', as written when the Message is sent.
+func (m *Message) TypeTags() string {
+	sb := strings.Builder{}
+	sb.WriteByte(',')
+	writeTypeTags(&sb, m.Arguments)
+	return sb.String()
+}
+
+// Matches reports whether the type tag string of the arguments equals
+// signature. The leading ',' of signature is optional, e.g. both ",if" and "if"
+// match a Message with an int32 and a float32 argument.
+func (m *Message) Matches(signature string) bool {
+	return strings.TrimPrefix(signature, ",") == m.TypeTags()[1:]
+}
+
+func argument[T any](m *Message, i int) (T, error) {
+	var zero T
+	if i < 0 || i >= len(m.Arguments) {
+		return zero, fmt.Errorf("argument index %d out of range, message has %d arguments", i, len(m.Arguments))
+	}
+	v, ok := m.Arguments[i].(T)
+	if !ok {
+		return zero, fmt.Errorf("argument %d is %T, not %T", i, m.Arguments[i], zero)
+	}
+	return v, nil
+}
@@ -0,0 +1,92 @@
+package gosc
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMessage_Int32(t *testing.T) {
+	msg := &Message{Address: "/test", Arguments: []any{int32(1), "a"}}
+	t.Run("correctType", func(t *testing.T) {
+		v, err := msg.Int32(0)
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+		if v != 1 {
+			t.Errorf("expected 1 but got: %d", v)
+		}
+	})
+	t.Run("wrongType", func(t *testing.T) {
+		_, err := msg.Int32(1)
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("outOfRange", func(t *testing.T) {
+		_, err := msg.Int32(2)
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+}
+
+func TestMessage_Float32(t *testing.T) {
+	msg := &Message{Address: "/test", Arguments: []any{float32(0.5)}}
+	v, err := msg.Float32(0)
+	if err != nil || v != 0.5 {
+		t.Errorf("expected 0.5 but got: %f (%v)", v, err)
+	}
+}
+
+func TestMessage_StringArg(t *testing.T) {
+	msg := &Message{Address: "/test", Arguments: []any{"a"}}
+	v, err := msg.StringArg(0)
+	if err != nil || v != "a" {
+		t.Errorf("expected \"a\" but got: %s (%v)", v, err)
+	}
+}
+
+func TestMessage_Blob(t *testing.T) {
+	msg := &Message{Address: "/test", Arguments: []any{[]byte{1, 2}}}
+	v, err := msg.Blob(0)
+	if err != nil || !bytes.Equal(v, []byte{1, 2}) {
+		t.Errorf("expected [1 2] but got: %v (%v)", v, err)
+	}
+}
+
+func TestMessage_Bool(t *testing.T) {
+	msg := &Message{Address: "/test", Arguments: []any{true}}
+	v, err := msg.Bool(0)
+	if err != nil || !v {
+		t.Errorf("expected true but got: %v (%v)", v, err)
+	}
+}
+
+func TestMessage_TypeTags(t *testing.T) {
+	msg := &Message{
+		Address:   "/test",
+		Arguments: []any{int32(1), float32(1), "a", []byte{}, true, false, []any{int32(1)}},
+	}
+	if tags := msg.TypeTags(); tags != ",ifsbTF[i]" {
+		t.Errorf("expected \",ifsbTF[i]\" but got: %s", tags)
+	}
+}
+
+func TestMessage_Matches(t *testing.T) {
+	msg := &Message{Address: "/test", Arguments: []any{int32(1), float32(1)}}
+	t.Run("withComma", func(t *testing.T) {
+		if !msg.Matches(",if") {
+			t.Error("expected message to match \",if\"")
+		}
+	})
+	t.Run("withoutComma", func(t *testing.T) {
+		if !msg.Matches("if") {
+			t.Error("expected message to match \"if\"")
+		}
+	})
+	t.Run("notMatching", func(t *testing.T) {
+		if msg.Matches(",fi") {
+			t.Error("expected message not to match \",fi\"")
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package gosc
+
+import (
+	"net"
+)
+
+// NewBroadcastTransport returns a UDP Transport for clients sending to a
+// broadcast address, e.g. "192.168.1.255:9000". Unlike NewUDPTransport the
+// socket is not connected, so the unicast responses of every host receiving
+// the broadcast are received. Go enables SO_BROADCAST on all UDP sockets.
+func NewBroadcastTransport(address string, bufferSize int) (Transport, error) {
+	return listenBroadcast(address, "", bufferSize)
+}
+
+// listenBroadcast returns a broadcast Transport bound to the local address, if
+// not empty.
+func listenBroadcast(address, localAddress string, bufferSize int) (Transport, error) {
+	if _, err := net.ResolveUDPAddr("udp4", address); err != nil {
+		return nil, err
+	}
+	laddr, err := resolveLocalUDPAddr(localAddress)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp4", laddr)
+	if err != nil {
+		return nil, err
+	}
+	return &transportPacket{
+		conn:       conn,
+		bufferSize: bufferSize,
+	}, nil
+}
+
+// isBroadcastIP reports whether ip is the limited broadcast address or the
+// broadcast address of one of the networks of the host.
+func isBroadcastIP(ip net.IP) bool {
+	ip = ip.To4()
+	if ip == nil {
+		return false
+	}
+	if ip.Equal(net.IPv4bcast) {
+		return true
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.To4() == nil || len(ipNet.Mask) != net.IPv4len {
+			continue
+		}
+		ones, bits := ipNet.Mask.Size()
+		if bits-ones < 2 {
+			continue
+		}
+		bcast := make(net.IP, net.IPv4len)
+		for i := range bcast {
+			bcast[i] = ipNet.IP.To4()[i] | ^ipNet.Mask[i]
+		}
+		if ip.Equal(bcast) {
+			return true
+		}
+	}
+	return false
+}
+
+// broadcastListenAddress returns address with the host removed, so that
+// broadcasts to the port are received on all networks of the host.
+func broadcastListenAddress(address string) string {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	return net.JoinHostPort("", port)
+}
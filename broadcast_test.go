@@ -0,0 +1,60 @@
+package gosc
+
+import (
+	"net"
+	"testing"
+)
+
+func Test_isBroadcastIP(t *testing.T) {
+	tests := map[string]bool{
+		"255.255.255.255": true,
+		"127.0.0.1":       false,
+		"239.0.0.1":       false,
+		"::1":             false,
+	}
+	for ip, expected := range tests {
+		if isBroadcastIP(net.ParseIP(ip)) != expected {
+			t.Errorf("expected isBroadcastIP(%q) to be %v", ip, expected)
+		}
+	}
+}
+
+func Test_broadcastListenAddress(t *testing.T) {
+	if addr := broadcastListenAddress("192.168.1.10:9000"); addr != ":9000" {
+		t.Errorf("expected \":9000\" but got: %q", addr)
+	}
+}
+
+func TestNewBroadcastTransport(t *testing.T) {
+	mux := NewMux(nil)
+	mux.HandleMessageFunc("/hello", func(w *ResponseWriter, msg *Message) {
+		_ = w.Send(&Message{Address: msg.Address, Arguments: []any{"World"}})
+	})
+	srv := NewServer(&ServerOptions{Broadcast: true})
+	trans, err := srv.listenTransport("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer trans.(*transportPacket).Close()
+	laddr := trans.(*transportPacket).conn.LocalAddr().(*net.UDPAddr)
+	if !laddr.IP.IsUnspecified() {
+		t.Errorf("expected server to listen on all addresses but got: %v", laddr)
+	}
+	go func() {
+		_ = srv.Serve(trans, mux)
+	}()
+
+	cli, err := NewBroadcastTransport("127.0.0.1:0", 512)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	c := NewClientWithTransport(cli, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: laddr.Port})
+	defer c.Close()
+	res, err := c.CallMessage("/hello")
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if s, _ := res.StringArg(0); s != "World" {
+		t.Errorf("expected response \"World\" but got: %v", res)
+	}
+}
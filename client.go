@@ -1,25 +1,110 @@
 package gosc
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"io"
+	"log/slog"
 	"net"
 	"regexp"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // A Client is an OSC client.
 type Client struct {
 	remote                 net.Addr
 	transport              Transport
+	transportMu            sync.RWMutex
 	messageReceivers       map[string]MessageReceiver
 	pendingMessageRequests sync.Map
 	bundleReceiver         BundleReceiver
+	receiversMu            sync.RWMutex
+	unpackBundles          bool
+	maxBundleSize          atomic.Int64
+	errorHandler           func(err error)
+	logger                 *slog.Logger
+	signer                 *Signer
+	redial                 func() (Transport, error)
+	reconnectInterval      time.Duration
+	closing                chan struct{}
+	closeOnce              sync.Once
+	done                   chan struct{}
+	err                    error
+}
+
+// ClientOptions is the configuration parameters used to create a Client with
+// NewClientWithOptions. The zero value is valid.
+type ClientOptions struct {
+	// LocalAddress is the local address, e.g. ":10024", the client binds to.
+	// Many devices reply to a fixed port rather than the source port of the
+	// request. An ephemeral port is used if empty.
+	LocalAddress string
+	// Transport is used instead of dialing the address, which is then only
+	// used as the remote address to send to and can be empty.
+	Transport Transport
+	// BufferSize is the largest datagram received, defaults to
+	// MaxDatagramSize.
+	BufferSize int
+	// ReadBuffer and WriteBuffer are the receive and send buffer sizes of
+	// datagram sockets, the system defaults are used if zero.
+	ReadBuffer  int
+	WriteBuffer int
+	// MaxPacketSize is the largest packet accepted on stream transports,
+	// defaults to DefaultMaxPacketSize.
+	MaxPacketSize int
+	// MaxBundleSize makes SendBundle split larger bundles, see
+	// Client.SetMaxBundleSize. Bundles are sent as is if zero.
+	MaxBundleSize int
+	// Multicast is used when the address is a multicast group address, nil
+	// uses the defaults.
+	Multicast *MulticastOptions
+	// ErrorHandler is called with the errors of receiving packages, e.g. a
+	// DecodeError or TruncatedError after which the package is skipped, and
+	// with the errors stopping the listener or failing to reconnect. Errors
+	// are ignored if nil. Panics of receivers are recovered and passed as a
+	// *PanicError, or logged with the stack trace if nil.
+	ErrorHandler func(err error)
+	// Reconnect makes the client dial the address again when the transport
+	// fails, e.g. when a TCP connection is closed by the server. It has no
+	// effect together with Transport.
+	Reconnect bool
+	// ReconnectInterval is the time between attempts to reconnect, defaults
+	// to one second.
+	ReconnectInterval time.Duration
+	// UnpackBundles passes the messages of received bundles, and of nested
+	// bundles, to the message receivers in addition to the bundle receiver.
+	UnpackBundles bool
+	// Logger is used to log the listener starting and stopping, reconnects,
+	// packages that are dropped or not routed, failed sends and recovered
+	// panics. Received packages are logged at the debug level. Nothing is
+	// logged if nil.
+	Logger *slog.Logger
+	// TLSConfig is used for "tls://" and "tls+slip://" addresses, e.g. to
+	// trust a private CA or to present a client certificate for mutual TLS.
+	// The system roots are trusted if nil.
+	TLSConfig *tls.Config
+	// Signer signs every package sent, to be verified by a Verifier of the
+	// server, if not nil. Responses are not verified.
+	Signer *Signer
 }
 
 type BundleReceiver interface {
 	ReceiveBundle(bundle *Bundle)
 }
 
+// BundleReceiverFunc type is an adapter to allow the use of ordinary functions
+// as BundleReceiver:s. If f a function with the appropriate signature,
+// BundleReceiverFunc(f) is a BundleReceiver that calls f.
+type BundleReceiverFunc func(bundle *Bundle)
+
+// ReceiveBundle calls b(bundle)
+func (b BundleReceiverFunc) ReceiveBundle(bundle *Bundle) {
+	b(bundle)
+}
+
 type MessageReceiver interface {
 	ReceiveMessage(msg *Message)
 }
@@ -37,25 +122,91 @@ func (m MessageReceiverFunc) ReceiveMessage(msg *Message) {
 // NewClient returns a default client with UDP transport to the given address.
 // The client will also start a go-routine to listen for data responses.
 //
-// The address must be a valid UDP-address including port number.
+// The address must be a valid UDP-address including port number. A TCP
+// transport is used if the address is prefixed with "tcp://", or
+// "tcp+slip://" for SLIP framing, TLS if prefixed with "tls://" or
+// "tls+slip://", and a unix datagram socket if prefixed with "unixgram://".
+// Packages are sent to the group if the UDP address is a multicast address,
+// and responses from all hosts are received if it is a broadcast address.
 func NewClient(address string) (*Client, error) {
-	remote, err := net.ResolveUDPAddr("udp", address)
-	if err != nil {
-		return nil, err
+	return NewClientWithOptions(address, nil)
+}
+
+// NewClientWithOptions returns a client to the given address, see NewClient,
+// with options applied. The opts can be nil to use the defaults.
+func NewClientWithOptions(address string, opts *ClientOptions) (*Client, error) {
+	if opts == nil {
+		opts = &ClientOptions{}
 	}
-	trans, err := NewUDPTransport(address, 512)
+	if opts.BufferSize == 0 {
+		opts.BufferSize = MaxDatagramSize
+	}
+
+	if opts.Transport != nil {
+		var remote net.Addr
+		if address != "" {
+			var err error
+			if remote, err = resolveRemote(address); err != nil {
+				return nil, err
+			}
+		}
+		return newClient(opts.Transport, remote, opts, nil), nil
+	}
+	dial := func() (Transport, net.Addr, error) {
+		trans, remote, err := dialTransport(address, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := setSocketBuffers(trans, opts.ReadBuffer, opts.WriteBuffer); err != nil {
+			_ = trans.(io.Closer).Close()
+			return nil, nil, err
+		}
+		return trans, remote, nil
+	}
+	trans, remote, err := dial()
 	if err != nil {
 		return nil, err
 	}
+	var redial func() (Transport, error)
+	if opts.Reconnect {
+		redial = func() (Transport, error) {
+			trans, _, err := dial()
+			return trans, err
+		}
+	}
+	return newClient(trans, remote, opts, redial), nil
+}
 
+// NewClientWithTransport returns a client sending packages to remote on the
+// given transport, e.g. one returned by NewStreamTransport. The remote can be
+// nil for transports that ignore the address. The client will also start a
+// go-routine to listen for data responses.
+func NewClientWithTransport(trans Transport, remote net.Addr) *Client {
+	return newClient(trans, remote, &ClientOptions{}, nil)
+}
+
+func newClient(trans Transport, remote net.Addr, opts *ClientOptions, redial func() (Transport, error)) *Client {
 	cli := &Client{
-		remote:           remote,
-		transport:        trans,
-		messageReceivers: map[string]MessageReceiver{},
+		remote:            remote,
+		transport:         trans,
+		messageReceivers:  map[string]MessageReceiver{},
+		unpackBundles:     opts.UnpackBundles,
+		errorHandler:      opts.ErrorHandler,
+		logger:            loggerOrDiscard(opts.Logger),
+		signer:            opts.Signer,
+		redial:            redial,
+		reconnectInterval: opts.ReconnectInterval,
+		closing:           make(chan struct{}),
+		done:              make(chan struct{}),
+	}
+	cli.maxBundleSize.Store(int64(opts.MaxBundleSize))
+	if cli.reconnectInterval <= 0 {
+		cli.reconnectInterval = time.Second
 	}
+	cli.logger.Info("client started", "remote", remote)
 	go cli.listen()
 
-	return cli, nil
+	return cli
 }
 
 // ReceiveMessage adds a MessageHandler for messages on a specific address using
@@ -65,6 +216,8 @@ func (c *Client) ReceiveMessage(addressPattern string, receiver MessageReceiver)
 	if err != nil {
 		return fmt.Errorf("addressPattern is not a valid regexp string: %v", err)
 	}
+	c.receiversMu.Lock()
+	defer c.receiversMu.Unlock()
 	c.messageReceivers[addressPattern] = receiver
 	return nil
 }
@@ -75,28 +228,101 @@ func (c *Client) ReceiveMessageFunc(addressPattern string, receiverFunc MessageR
 	return c.ReceiveMessage(addressPattern, receiverFunc)
 }
 
+// ReceiveBundle sets the BundleReceiver for received bundles, replacing any
+// previous one. Bundles are dropped if no receiver is set.
+func (c *Client) ReceiveBundle(receiver BundleReceiver) {
+	c.receiversMu.Lock()
+	defer c.receiversMu.Unlock()
+	c.bundleReceiver = receiver
+}
+
+// ReceiveBundleFunc sets a BundleReceiverFunc for received bundles, see
+// ReceiveBundle.
+func (c *Client) ReceiveBundleFunc(receiverFunc BundleReceiverFunc) {
+	c.ReceiveBundle(receiverFunc)
+}
+
+// Close closes the transport of the client, which also stops the listener.
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closing)
+	})
+	if closer, ok := c.getTransport().(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Done returns a channel that is closed when the listener of the client has
+// stopped, see Err.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the error that stopped the listener of the client, or nil while
+// it is still running. Decode errors of single packages do not stop the
+// listener.
+func (c *Client) Err() error {
+	select {
+	case <-c.done:
+		return c.err
+	default:
+		return nil
+	}
+}
+
 // SendMessage uses the clients transport to encode and send an OSC Message
 func (c *Client) SendMessage(msg *Message) error {
-	return c.transport.Send(msg, c.remote)
+	return c.send(msg)
+}
+
+// SetMaxBundleSize makes SendBundle split bundles larger than size bytes into
+// several bundles with the same timetag, see SplitBundle. Bundles are sent as
+// is if size is zero, which is the default. It is safe to call while sending.
+func (c *Client) SetMaxBundleSize(size int) {
+	c.maxBundleSize.Store(int64(size))
 }
 
 // SendBundle uses the clients transport to encode and send an OSC Bundle
 func (c *Client) SendBundle(bun *Bundle) error {
-	return c.transport.Send(bun, c.remote)
+	maxSize := int(c.maxBundleSize.Load())
+	if maxSize <= 0 {
+		return c.send(bun)
+	}
+	if c.signer != nil {
+		maxSize -= c.signer.overhead()
+	}
+	bundles, err := SplitBundle(bun, maxSize)
+	if err != nil {
+		return err
+	}
+	for _, b := range bundles {
+		if err := c.send(b); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // SendAndReceiveMessage sends the OSC Message using the clients transport and
-// then waits (blocking) for the response to arrive to the listener.
+// then waits (blocking) for the response to arrive to the listener. The error
+// of the listener is returned if it stops before the response arrives.
 func (c *Client) SendAndReceiveMessage(msg *Message) (*Message, error) {
 	ch := make(chan *Message)
 	c.pendingMessageRequests.Store(msg.Address, ch)
 	err := c.SendMessage(msg)
 	if err != nil {
+		c.pendingMessageRequests.Delete(msg.Address)
 		return nil, err
 	}
 
-	res := <-ch
-	return res, err
+	select {
+	case res := <-ch:
+		return res, nil
+	case <-c.done:
+		c.pendingMessageRequests.Delete(msg.Address)
+		return nil, c.err
+	}
 }
 
 // EmitMessage creates an OSC Message using the provided data and then sends it.
@@ -116,30 +342,168 @@ func (c *Client) CallMessage(address string, varArg ...any) (*Message, error) {
 	})
 }
 
+func (c *Client) send(pkg Package) error {
+	signed := pkg
+	if c.signer != nil {
+		msg, err := c.signer.Sign(pkg)
+		if err != nil {
+			return err
+		}
+		signed = msg
+	}
+	err := c.getTransport().Send(signed, c.remote)
+	if err != nil {
+		c.logger.Warn("send failed", append([]any{"dst", c.remote, "error", err}, packageAttrs(c.logger, pkg)...)...)
+	}
+	return err
+}
+
 func (c *Client) listen() {
-	var pkg Package
-	var err error
-	for pkg, _, err = c.transport.Receive(); err == nil; pkg, _, err = c.transport.Receive() {
-		if pkg.GetType() == PackageTypeMessage {
-			m := pkg.(*Message)
-			if chi, ok := c.pendingMessageRequests.LoadAndDelete(m.Address); ok {
-				ch := chi.(chan *Message)
-				ch <- m
-				close(ch)
-			} else {
-				for pattern, h := range c.messageReceivers {
-					if c.addressMatches(pattern, m.Address) {
-						h.ReceiveMessage(m)
-						break
-					}
-				}
+	defer close(c.done)
+	for {
+		pkg, from, err := c.getTransport().Receive()
+		if err == nil {
+			c.handlePackage(pkg, from)
+			continue
+		}
+		if c.isClosing() {
+			c.err = net.ErrClosed
+			c.logger.Info("client closed")
+			return
+		}
+		c.handleError(err)
+		if isTransientError(err) {
+			c.logger.Warn("dropped package", "src", from, "error", err)
+			continue
+		}
+		c.logger.Error("listener failed", "error", err)
+		if c.redial == nil || !c.reconnect() {
+			c.err = err
+			return
+		}
+	}
+}
+
+// reconnect replaces the failed transport with a new one, retrying until it
+// succeeds or the client is closed.
+func (c *Client) reconnect() bool {
+	if closer, ok := c.getTransport().(io.Closer); ok {
+		_ = closer.Close()
+	}
+	for {
+		select {
+		case <-c.closing:
+			return false
+		case <-time.After(c.reconnectInterval):
+		}
+		c.logger.Info("reconnecting", "remote", c.remote)
+		trans, err := c.redial()
+		if err != nil {
+			c.logger.Warn("reconnect failed", "remote", c.remote, "error", err)
+			c.handleError(err)
+			continue
+		}
+		c.transportMu.Lock()
+		c.transport = trans
+		c.transportMu.Unlock()
+		if c.isClosing() {
+			_ = trans.(io.Closer).Close()
+			return false
+		}
+		c.logger.Info("reconnected", "remote", c.remote)
+		return true
+	}
+}
+
+func (c *Client) getTransport() Transport {
+	c.transportMu.RLock()
+	defer c.transportMu.RUnlock()
+	return c.transport
+}
+
+func (c *Client) isClosing() bool {
+	select {
+	case <-c.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Client) handleError(err error) {
+	if c.errorHandler != nil {
+		c.errorHandler(err)
+	}
+}
+
+// handlePackage passes pkg to the receivers, recovering from panics.
+func (c *Client) handlePackage(pkg Package, from net.Addr) {
+	defer func() {
+		if v := recover(); v != nil {
+			err := newPanicError(v, from, pkg)
+			if c.logger != discardLogger {
+				logPanicTo(c.logger, err)
 			}
-		} else if pkg.GetType() == PackageTypeBundle {
-			if c.bundleReceiver != nil {
-				b := pkg.(*Bundle)
-				c.bundleReceiver.ReceiveBundle(b)
+			if c.errorHandler != nil {
+				c.errorHandler(err)
+			} else if c.logger == discardLogger {
+				logPanic(err)
 			}
 		}
+	}()
+	if c.logger.Enabled(context.Background(), slog.LevelDebug) {
+		c.logger.Debug("received package", append([]any{"src", from}, packageAttrs(c.logger, pkg)...)...)
+	}
+	if pkg.GetType() == PackageTypeMessage {
+		c.handleMessage(pkg.(*Message))
+	} else if pkg.GetType() == PackageTypeBundle {
+		c.handleBundle(pkg.(*Bundle))
+	}
+}
+
+func (c *Client) handleMessage(m *Message) {
+	if chi, ok := c.pendingMessageRequests.LoadAndDelete(m.Address); ok {
+		ch := chi.(chan *Message)
+		ch <- m
+		close(ch)
+		return
+	}
+	var receiver MessageReceiver
+	c.receiversMu.RLock()
+	for pattern, h := range c.messageReceivers {
+		if c.addressMatches(pattern, m.Address) {
+			receiver = h
+			break
+		}
+	}
+	c.receiversMu.RUnlock()
+	if receiver == nil {
+		c.logger.Info("unrouted message", "address", m.Address, "types", m.TypeTags())
+		return
+	}
+	receiver.ReceiveMessage(m)
+}
+
+func (c *Client) handleBundle(b *Bundle) {
+	c.receiversMu.RLock()
+	receiver := c.bundleReceiver
+	c.receiversMu.RUnlock()
+	if receiver != nil {
+		receiver.ReceiveBundle(b)
+	}
+	if c.unpackBundles {
+		c.unpackBundle(b)
+	}
+}
+
+// unpackBundle passes the messages of b, and of its nested bundles, to
+// handleMessage.
+func (c *Client) unpackBundle(b *Bundle) {
+	for _, m := range b.Messages {
+		c.handleMessage(m)
+	}
+	for _, nested := range b.Bundles {
+		c.unpackBundle(nested)
 	}
 }
 
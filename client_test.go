@@ -1,7 +1,11 @@
 package gosc
 
 import (
+	"errors"
+	"io"
+	"net"
 	"testing"
+	"time"
 )
 
 type testMessageReceiver struct {
@@ -12,6 +16,70 @@ func (t *testMessageReceiver) ReceiveMessage(_ *Message) {
 	t.received = true
 }
 
+func TestNewClientWithOptions(t *testing.T) {
+	srv, err := NewUDPListen("127.0.0.1:0", 0)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer srv.(*transportPacket).Close()
+	address := srv.(*transportPacket).conn.LocalAddr().String()
+
+	t.Run("localAddress", func(t *testing.T) {
+		local, _ := NewUDPListen("127.0.0.1:0", 0)
+		localAddress := local.(*transportPacket).conn.LocalAddr().String()
+		_ = local.(*transportPacket).Close()
+
+		cli, err := NewClientWithOptions(address, &ClientOptions{LocalAddress: localAddress})
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		defer cli.Close()
+		_ = cli.EmitMessage("/test")
+		_, from, err := srv.Receive()
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if from.String() != localAddress {
+			t.Errorf("expected message from %s but got: %v", localAddress, from)
+		}
+	})
+	t.Run("transport", func(t *testing.T) {
+		trans := &testTransport{}
+		cli, err := NewClientWithOptions("", &ClientOptions{Transport: trans})
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		_ = cli.EmitMessage("/test")
+		if len(trans.sent) != 1 {
+			t.Errorf("expected message to be sent on transport but got: %v", trans.sent)
+		}
+	})
+	t.Run("errorHandler", func(t *testing.T) {
+		errs := make(chan error, 1)
+		cli, err := NewClientWithOptions(address, &ClientOptions{
+			BufferSize:   16,
+			ErrorHandler: func(err error) { errs <- err },
+		})
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		defer cli.Close()
+		_ = cli.EmitMessage("/test")
+		_, from, _ := srv.Receive()
+		_ = srv.Send(&Message{Address: "/test", Arguments: []any{make([]byte, 16)}}, from)
+		var truncated *TruncatedError
+		if err := <-errs; !errors.As(err, &truncated) {
+			t.Errorf("expected TruncatedError but got: %v", err)
+		}
+	})
+	t.Run("unsupportedNetwork", func(t *testing.T) {
+		_, err := NewClientWithOptions("sctp://127.0.0.1:1234", nil)
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+}
+
 func TestClient_CallMessage(t *testing.T) {
 	// TODO: Implement me
 }
@@ -52,8 +120,134 @@ func TestClient_SendAndReceiveMessage(t *testing.T) {
 	// TODO: Implement me
 }
 
+func TestClient_ReceiveBundle(t *testing.T) {
+	bundle := &Bundle{
+		Timetag:  Immediately,
+		Messages: []*Message{{Address: "/meter/1", Arguments: []any{float32(0.5)}}},
+		Bundles:  []*Bundle{{Timetag: Immediately, Messages: []*Message{{Address: "/meter/2"}}}},
+	}
+	t.Run("receiver", func(t *testing.T) {
+		cli := NewClientWithTransport(&testTransport{}, nil)
+		var received *Bundle
+		cli.ReceiveBundleFunc(func(b *Bundle) { received = b })
+		cli.handleBundle(bundle)
+		if received != bundle {
+			t.Errorf("expected bundle to be received but got: %v", received)
+		}
+	})
+	t.Run("unpackBundles", func(t *testing.T) {
+		cli, _ := NewClientWithOptions("", &ClientOptions{Transport: &testTransport{}, UnpackBundles: true})
+		var addresses []string
+		_ = cli.ReceiveMessageFunc("/meter/.*", func(msg *Message) {
+			addresses = append(addresses, msg.Address)
+		})
+		cli.handleBundle(bundle)
+		if len(addresses) != 2 || addresses[0] != "/meter/1" || addresses[1] != "/meter/2" {
+			t.Errorf("expected messages to be unpacked but got: %v", addresses)
+		}
+	})
+	t.Run("transport", func(t *testing.T) {
+		a, b := net.Pipe()
+		cli := NewClientWithTransport(NewStreamTransport(a, FramingSizePrefix), nil)
+		defer cli.Close()
+		received := make(chan *Bundle, 1)
+		cli.ReceiveBundle(BundleReceiverFunc(func(b *Bundle) { received <- b }))
+		srv := NewStreamTransport(b, FramingSizePrefix)
+		_ = srv.Send(bundle, nil)
+		if b := <-received; len(b.Messages) != 1 || len(b.Bundles) != 1 {
+			t.Errorf("expected bundle to be received but got: %v", b)
+		}
+	})
+}
+
+func TestClient_handlePackage(t *testing.T) {
+	a, _ := net.Pipe()
+	errs := make(chan error, 1)
+	cli, _ := NewClientWithOptions("", &ClientOptions{
+		Transport:    NewStreamTransport(a, FramingSizePrefix),
+		ErrorHandler: func(err error) { errs <- err },
+	})
+	defer cli.Close()
+	_ = cli.ReceiveMessageFunc("/crash", func(msg *Message) {
+		_ = msg.Arguments[0]
+	})
+	cli.handlePackage(&Message{Address: "/crash"}, nil)
+	var panicErr *PanicError
+	if err := <-errs; !errors.As(err, &panicErr) {
+		t.Errorf("expected PanicError but got: %v", err)
+	}
+}
+
 func TestClient_SendBundle(t *testing.T) {
-	// TODO: Implement me
+	bundle := &Bundle{Timetag: Immediately}
+	for i := 0; i < 10; i++ {
+		bundle.Messages = append(bundle.Messages, &Message{Address: "/meter", Arguments: []any{int32(i)}})
+	}
+	t.Run("unlimited", func(t *testing.T) {
+		trans := &testTransport{}
+		cli := NewClientWithTransport(trans, nil)
+		_ = cli.SendBundle(bundle)
+		if len(trans.sent) != 1 {
+			t.Errorf("expected 1 bundle sent but got: %d", len(trans.sent))
+		}
+	})
+	t.Run("maxBundleSize", func(t *testing.T) {
+		trans := &testTransport{}
+		cli := NewClientWithTransport(trans, nil)
+		cli.SetMaxBundleSize(100)
+		if err := cli.SendBundle(bundle); err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if len(trans.sent) != 3 {
+			t.Errorf("expected 3 bundles sent but got: %d", len(trans.sent))
+		}
+	})
+	t.Run("maxPacketSize", func(t *testing.T) {
+		trans := &testTransport{}
+		cli, _ := NewClientWithOptions("", &ClientOptions{Transport: trans, MaxPacketSize: 100})
+		_ = cli.SendBundle(bundle)
+		if len(trans.sent) != 1 {
+			t.Errorf("expected receive limit not to split bundles but got %d bundles", len(trans.sent))
+		}
+	})
+	t.Run("concurrentSetMaxBundleSize", func(t *testing.T) {
+		a, b := net.Pipe()
+		go func() { _, _ = io.Copy(io.Discard, b) }()
+		cli := NewClientWithTransport(NewStreamTransport(a, FramingSizePrefix), nil)
+		defer cli.Close()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				_ = cli.SendBundle(bundle)
+			}
+		}()
+		for i := 0; i < 100; i++ {
+			cli.SetMaxBundleSize(100 + i)
+		}
+		<-done
+	})
+	t.Run("signed", func(t *testing.T) {
+		for maxSize := 180; maxSize < 200; maxSize++ {
+			trans := &testTransport{}
+			cli, _ := NewClientWithOptions("", &ClientOptions{
+				Transport:     trans,
+				MaxBundleSize: maxSize,
+				Signer:        NewSigner("k1", []byte("secret")),
+			})
+			if err := cli.SendBundle(bundle); err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			if len(trans.sent) < 2 {
+				t.Errorf("expected bundle to be split for %d bytes but got %d bundles", maxSize, len(trans.sent))
+			}
+			for _, pkg := range trans.sent {
+				if size, _ := encodedSize(pkg); size > maxSize {
+					t.Errorf("expected signed bundles of at most %d bytes but got: %d", maxSize, size)
+				}
+			}
+		}
+	})
 }
 
 func TestClient_SendMessage(t *testing.T) {
@@ -71,7 +265,94 @@ func TestClient_addressMatches(t *testing.T) {
 }
 
 func TestClient_listen(t *testing.T) {
-	// TODO: Implement me
+	t.Run("decodeError", func(t *testing.T) {
+		srv, _ := NewUDPListen("127.0.0.1:0", 0)
+		defer srv.(*transportPacket).Close()
+		errs := make(chan error, 1)
+		cli, err := NewClientWithOptions(srv.(*transportPacket).conn.LocalAddr().String(), &ClientOptions{
+			ErrorHandler: func(err error) { errs <- err },
+		})
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		defer cli.Close()
+		received := make(chan *Message, 1)
+		_ = cli.ReceiveMessageFunc("/test", func(msg *Message) { received <- msg })
+
+		_ = cli.EmitMessage("/hello")
+		_, from, _ := srv.Receive()
+		_, _ = srv.(*transportPacket).conn.WriteTo([]byte("garbage"), from)
+		_ = srv.Send(&Message{Address: "/test"}, from)
+		var decodeErr *DecodeError
+		if err := <-errs; !errors.As(err, &decodeErr) {
+			t.Errorf("expected DecodeError but got: %v", err)
+		}
+		if msg := <-received; msg.Address != "/test" {
+			t.Errorf("expected message after decode error but got: %v", msg)
+		}
+		if err := cli.Err(); err != nil {
+			t.Errorf("expected listener to be running but got: %v", err)
+		}
+	})
+	t.Run("fatalError", func(t *testing.T) {
+		a, b := net.Pipe()
+		cli := NewClientWithTransport(NewStreamTransport(a, FramingSizePrefix), nil)
+		defer cli.Close()
+		go func() {
+			_, _ = b.Read(make([]byte, 64))
+			_ = b.Close()
+		}()
+		if _, err := cli.CallMessage("/hello"); !errors.Is(err, io.EOF) {
+			t.Errorf("expected io.EOF but got: %v", err)
+		}
+		<-cli.Done()
+		if err := cli.Err(); !errors.Is(err, io.EOF) {
+			t.Errorf("expected io.EOF but got: %v", err)
+		}
+	})
+	t.Run("close", func(t *testing.T) {
+		a, _ := net.Pipe()
+		cli := NewClientWithTransport(NewStreamTransport(a, FramingSizePrefix), nil)
+		_ = cli.Close()
+		<-cli.Done()
+		if err := cli.Err(); !errors.Is(err, net.ErrClosed) {
+			t.Errorf("expected net.ErrClosed but got: %v", err)
+		}
+	})
+	t.Run("reconnect", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		defer ln.Close()
+		cli, err := NewClientWithOptions("tcp://"+ln.Addr().String(), &ClientOptions{
+			Reconnect:         true,
+			ReconnectInterval: 10 * time.Millisecond,
+		})
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		defer cli.Close()
+		conn, _ := ln.Accept()
+		_ = conn.Close()
+
+		conn, err = ln.Accept()
+		if err != nil {
+			t.Fatalf("expected client to reconnect but got: %v", err)
+		}
+		defer conn.Close()
+		trans := NewStreamTransport(conn, FramingSizePrefix)
+		_ = trans.Send(&Message{Address: "/ping"}, nil)
+		for cli.EmitMessage("/pong") != nil {
+			time.Sleep(time.Millisecond)
+		}
+		if pkg, _, err := trans.Receive(); err != nil || pkg.(*Message).Address != "/pong" {
+			t.Errorf("expected /pong on new connection but got: %v, %v", pkg, err)
+		}
+		if err := cli.Err(); err != nil {
+			t.Errorf("expected listener to be running but got: %v", err)
+		}
+	})
 }
 
 func TestNewClient(t *testing.T) {
@@ -0,0 +1,47 @@
+package gosc
+
+import (
+	"context"
+	"log/slog"
+)
+
+// DropPolicy is what a middleware, e.g. RateLimiter, does with the packages it
+// drops.
+type DropPolicy int
+
+// Constants for the drop policies of middlewares.
+const (
+	// DropSilently drops the package without a response.
+	DropSilently DropPolicy = iota
+	// DropAndReply drops the package and replies to the sender with a
+	// Message on ErrorAddress with the address of the package and the reason,
+	// e.g. "rate limited".
+	DropAndReply
+)
+
+// drop handles a package dropped by a middleware because of err. It is logged
+// at level, reported to the Metrics of w and answered according to policy.
+// The writer can be nil.
+func drop(w *ResponseWriter, pkg Package, err error, level slog.Level, policy DropPolicy) {
+	if w == nil {
+		return
+	}
+	w.log().Log(context.Background(), level, "dropped package",
+		"src", w.src, "address", packageAddress(pkg), "error", err)
+	w.metricsOrNop().PackageDropped(w.src, err)
+	if policy == DropAndReply {
+		_ = w.Send(&Message{
+			Address:   ErrorAddress,
+			Arguments: []any{packageAddress(pkg), err.Error()},
+		})
+	}
+}
+
+// packageAddress returns the address of pkg, or "#bundle" for bundles, e.g.
+// to reply with on ErrorAddress.
+func packageAddress(pkg Package) string {
+	if msg, ok := pkg.(*Message); ok {
+		return msg.Address
+	}
+	return "#bundle"
+}
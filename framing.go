@@ -0,0 +1,148 @@
+package gosc
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Framing is the method used to separate packets on stream transports.
+type Framing int
+
+// Constants for the supported stream framings.
+const (
+	// FramingSizePrefix prefixes every packet with its int32 size as specified
+	// by OSC 1.0.
+	FramingSizePrefix Framing = iota
+	// FramingSLIP encodes every packet with double END SLIP (RFC 1055) as
+	// specified by OSC 1.1.
+	FramingSLIP
+)
+
+// SLIP special characters as defined in RFC 1055.
+const (
+	slipEnd    = 0xC0
+	slipEsc    = 0xDB
+	slipEscEnd = 0xDC
+	slipEscEsc = 0xDD
+)
+
+func (f Framing) String() string {
+	switch f {
+	case FramingSizePrefix:
+		return "size-prefix"
+	case FramingSLIP:
+		return "slip"
+	}
+	return fmt.Sprintf("Framing(%d)", int(f))
+}
+
+// writeFrame writes data as a single frame.
+func (f Framing) writeFrame(w io.Writer, data []byte) error {
+	if f == FramingSLIP {
+		return writeSLIPFrame(w, data)
+	}
+	return writeSizedFrame(w, data)
+}
+
+// readFrame reads the next frame. A SLIP frame larger than maxSize is skipped
+// and returned as frameErr since the stream can still be read, err is set when
+// reading the stream failed or a size-prefixed frame is larger than maxSize.
+func (f Framing) readFrame(r *bufio.Reader, maxSize int) (frame []byte, frameErr, err error) {
+	if f == FramingSLIP {
+		return readSLIPFrame(r, maxSize)
+	}
+	return readSizedFrame(r, maxSize)
+}
+
+// writeSizedFrame writes data prefixed with its int32 size.
+func writeSizedFrame(w io.Writer, data []byte) error {
+	frame := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(frame, uint32(len(data)))
+	copy(frame[4:], data)
+	_, err := w.Write(frame)
+	return err
+}
+
+// readSizedFrame reads a frame written by writeSizedFrame. Frames larger than
+// maxSize fail the stream instead of being discarded, since the peer could
+// otherwise keep the reader busy with up to 2 GiB per frame.
+func readSizedFrame(r *bufio.Reader, maxSize int) (frame []byte, frameErr, err error) {
+	size := int32(0)
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, nil, err
+	}
+	if size < 0 {
+		return nil, nil, fmt.Errorf("negative packet size %d", size)
+	}
+	if int(size) > maxSize {
+		return nil, nil, fmt.Errorf("%w: size %d exceeds maximum %d", ErrPacketTooLarge, size, maxSize)
+	}
+	frame = make([]byte, size)
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return nil, nil, err
+	}
+	return frame, nil, nil
+}
+
+// writeSLIPFrame writes data between two END characters, escaping END and ESC
+// characters in data.
+func writeSLIPFrame(w io.Writer, data []byte) error {
+	frame := make([]byte, 0, len(data)+len(data)/8+2)
+	frame = append(frame, slipEnd)
+	for _, b := range data {
+		switch b {
+		case slipEnd:
+			frame = append(frame, slipEsc, slipEscEnd)
+		case slipEsc:
+			frame = append(frame, slipEsc, slipEscEsc)
+		default:
+			frame = append(frame, b)
+		}
+	}
+	frame = append(frame, slipEnd)
+	_, err := w.Write(frame)
+	return err
+}
+
+// readSLIPFrame reads a frame written by writeSLIPFrame. Empty frames, e.g.
+// between two END characters, are skipped. As suggested by RFC 1055, an ESC
+// character followed by anything else than ESC_END or ESC_ESC is kept as is.
+func readSLIPFrame(r *bufio.Reader, maxSize int) (frame []byte, frameErr, err error) {
+	frame = make([]byte, 0, 64)
+	escaped := false
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case b == slipEnd:
+			if len(frame) == 0 && frameErr == nil {
+				continue
+			}
+			return frame, frameErr, nil
+		case escaped:
+			escaped = false
+			switch b {
+			case slipEscEnd:
+				b = slipEnd
+			case slipEscEsc:
+				b = slipEsc
+			}
+		case b == slipEsc:
+			escaped = true
+			continue
+		}
+		if frameErr != nil {
+			continue
+		}
+		if len(frame) >= maxSize {
+			frame = frame[:0]
+			frameErr = fmt.Errorf("packet size exceeds maximum %d", maxSize)
+			continue
+		}
+		frame = append(frame, b)
+	}
+}
@@ -0,0 +1,96 @@
+package gosc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestFraming_readFrame(t *testing.T) {
+	for _, framing := range []Framing{FramingSizePrefix, FramingSLIP} {
+		t.Run(framing.String(), func(t *testing.T) {
+			buf := bytes.Buffer{}
+			_ = framing.writeFrame(&buf, []byte{1, slipEnd, 2, slipEsc, 3})
+			_ = framing.writeFrame(&buf, make([]byte, 32))
+			_ = framing.writeFrame(&buf, []byte("/test"))
+			r := bufio.NewReader(&buf)
+
+			frame, frameErr, err := framing.readFrame(r, 16)
+			if err != nil || frameErr != nil {
+				t.Errorf("expected no error but got: %v, %v", frameErr, err)
+			}
+			if !bytes.Equal(frame, []byte{1, slipEnd, 2, slipEsc, 3}) {
+				t.Errorf("expected frame to be read back but got: %v", frame)
+			}
+			_, frameErr, err = framing.readFrame(r, 16)
+			if framing == FramingSizePrefix {
+				if !errors.Is(err, ErrPacketTooLarge) || frameErr != nil {
+					t.Errorf("expected ErrPacketTooLarge for large frame but got: %v, %v", frameErr, err)
+				}
+				return
+			}
+			if err != nil || frameErr == nil {
+				t.Errorf("expected frame error for large frame but got: %v, %v", frameErr, err)
+			}
+			frame, _, _ = framing.readFrame(r, 16)
+			if string(frame) != "/test" {
+				t.Errorf("expected frame after large frame to be read but got: %s", frame)
+			}
+		})
+	}
+}
+
+func Test_readSizedFrame(t *testing.T) {
+	// A frame announcing 2 GiB must fail without reading the announced bytes.
+	r := bufio.NewReader(io.MultiReader(bytes.NewReader([]byte{0x7f, 0xff, 0xff, 0xff}), neverReader{}))
+	_, _, err := readSizedFrame(r, 16)
+	if !errors.Is(err, ErrPacketTooLarge) {
+		t.Errorf("expected ErrPacketTooLarge but got: %v", err)
+	}
+}
+
+// neverReader blocks forever when read, timing out tests reading too much.
+type neverReader struct{}
+
+func (neverReader) Read([]byte) (int, error) {
+	select {}
+}
+
+func Test_writeSizedFrame(t *testing.T) {
+	buf := bytes.Buffer{}
+	_ = writeSizedFrame(&buf, []byte("/test"))
+	if !bytes.Equal(buf.Bytes(), []byte{0, 0, 0, 5, '/', 't', 'e', 's', 't'}) {
+		t.Errorf("unexpected frame: %v", buf.Bytes())
+	}
+}
+
+func Test_writeSLIPFrame(t *testing.T) {
+	buf := bytes.Buffer{}
+	_ = writeSLIPFrame(&buf, []byte{1, slipEnd, slipEsc})
+	expected := []byte{slipEnd, 1, slipEsc, slipEscEnd, slipEsc, slipEscEsc, slipEnd}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("expected frame %v but got: %v", expected, buf.Bytes())
+	}
+}
+
+func Test_readSLIPFrame(t *testing.T) {
+	t.Run("emptyFramesSkipped", func(t *testing.T) {
+		r := bufio.NewReader(bytes.NewReader([]byte{slipEnd, slipEnd, slipEnd, 1, 2, slipEnd}))
+		frame, _, err := readSLIPFrame(r, 16)
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+		if !bytes.Equal(frame, []byte{1, 2}) {
+			t.Errorf("expected [1 2] but got: %v", frame)
+		}
+	})
+	t.Run("invalidEscapeKept", func(t *testing.T) {
+		r := bufio.NewReader(bytes.NewReader([]byte{slipEnd, slipEsc, 7, slipEnd}))
+		frame, _, _ := readSLIPFrame(r, 16)
+		if !bytes.Equal(frame, []byte{7}) {
+			t.Errorf("expected [7] but got: %v", frame)
+		}
+	})
+}
@@ -1,3 +1,7 @@
 module github.com/loffa/gosc
 
-go 1.18
+go 1.21
+
+require golang.org/x/net v0.35.0
+
+require golang.org/x/sys v0.30.0 // indirect
@@ -0,0 +1,104 @@
+// Package gosctest provides utilities for testing OSC handlers without binding
+// network ports, similar to net/http/httptest.
+package gosctest
+
+import (
+	"io"
+	"net"
+	"sync"
+
+	"github.com/loffa/gosc"
+)
+
+// NewPipe returns two connected in-memory transports. Packages sent on one of
+// them are received on the other, encoded and decoded just like on a real
+// stream transport.
+func NewPipe() (gosc.Transport, gosc.Transport) {
+	a, b := net.Pipe()
+	return gosc.NewStreamTransport(a, gosc.FramingSizePrefix),
+		gosc.NewStreamTransport(b, gosc.FramingSizePrefix)
+}
+
+// A Server is an OSC server serving a handler on an in-memory transport.
+type Server struct {
+	// Client is connected to the server and receives its responses.
+	Client *gosc.Client
+
+	transport gosc.Transport
+	done      chan struct{}
+}
+
+// NewServer starts and returns a Server calling handler for the packages sent
+// by Server.Client. The caller should call Close when finished.
+func NewServer(handler gosc.PackageHandler) *Server {
+	cli, srv := NewPipe()
+	s := &Server{
+		Client:    gosc.NewClientWithTransport(cli, nil),
+		transport: srv,
+		done:      make(chan struct{}),
+	}
+	go func() {
+		defer close(s.done)
+		_ = gosc.NewServer(&gosc.ServerOptions{}).Serve(srv, handler)
+	}()
+	return s
+}
+
+// Close closes the client and the server transport and waits for the server
+// to stop.
+func (s *Server) Close() {
+	_ = s.Client.Close()
+	_ = s.transport.(io.Closer).Close()
+	<-s.done
+}
+
+// ResponseRecorder is a gosc.Transport recording the packages sent on it, for
+// inspection in tests.
+type ResponseRecorder struct {
+	mu       sync.Mutex
+	packages []gosc.Package
+}
+
+// NewRecorder returns an initialized ResponseRecorder.
+func NewRecorder() *ResponseRecorder {
+	return &ResponseRecorder{}
+}
+
+// ResponseWriter returns a gosc.ResponseWriter sending responses to the
+// recorder, to be passed to the handler under test.
+func (r *ResponseRecorder) ResponseWriter() *gosc.ResponseWriter {
+	return gosc.NewResponseWriter(r, nil)
+}
+
+// Send records the Package, the address is ignored.
+func (r *ResponseRecorder) Send(pack gosc.Package, _ net.Addr) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.packages = append(r.packages, pack)
+	return nil
+}
+
+// Receive always returns io.EOF since the recorder only records sent
+// packages.
+func (r *ResponseRecorder) Receive() (gosc.Package, net.Addr, error) {
+	return nil, nil, io.EOF
+}
+
+// Packages returns the packages sent so far, in order.
+func (r *ResponseRecorder) Packages() []gosc.Package {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]gosc.Package(nil), r.packages...)
+}
+
+// Messages returns the messages sent so far, in order. Bundles are not
+// included.
+func (r *ResponseRecorder) Messages() []*gosc.Message {
+	var msgs []*gosc.Message
+	for _, pack := range r.Packages() {
+		if msg, ok := pack.(*gosc.Message); ok {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
@@ -0,0 +1,59 @@
+package gosctest
+
+import (
+	"testing"
+
+	"github.com/loffa/gosc"
+)
+
+func helloHandler() *gosc.Mux {
+	mux := gosc.NewMux(nil)
+	mux.HandleMessageFunc("/hello", func(w *gosc.ResponseWriter, msg *gosc.Message) {
+		_ = w.Send(&gosc.Message{Address: msg.Address, Arguments: []any{"World"}})
+	})
+	return mux
+}
+
+func TestNewPipe(t *testing.T) {
+	a, b := NewPipe()
+	go func() {
+		_ = a.Send(&gosc.Message{Address: "/test", Arguments: []any{int32(1)}}, nil)
+	}()
+	pkg, _, err := b.Receive()
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if v, _ := pkg.(*gosc.Message).Int32(0); v != 1 {
+		t.Errorf("expected argument 1 but got: %v", pkg)
+	}
+}
+
+func TestNewServer(t *testing.T) {
+	srv := NewServer(helloHandler())
+	defer srv.Close()
+
+	res, err := srv.Client.CallMessage("/hello")
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if s, _ := res.StringArg(0); s != "World" {
+		t.Errorf("expected response \"World\" but got: %v", res)
+	}
+}
+
+func TestResponseRecorder(t *testing.T) {
+	rec := NewRecorder()
+	helloHandler().HandlePackage(rec.ResponseWriter(), &gosc.Message{Address: "/hello"})
+	helloHandler().HandlePackage(rec.ResponseWriter(), &gosc.Message{Address: "/unknown"})
+
+	msgs := rec.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 recorded message but got: %d", len(msgs))
+	}
+	if s, _ := msgs[0].StringArg(0); s != "World" {
+		t.Errorf("expected response \"World\" but got: %v", msgs[0])
+	}
+	if len(rec.Packages()) != 1 {
+		t.Errorf("expected 1 recorded package but got: %d", len(rec.Packages()))
+	}
+}
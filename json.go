@@ -0,0 +1,426 @@
+package gosc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// The JSON encoding of a Message keeps the type of every argument, either as
+// a list of typed arguments:
+//
+//	{"address":"/a","args":[{"type":"f","value":0.5},{"type":"T"}]}
+//
+// or in the compact form, with the type tag string and plain values:
+//
+//	{"address":"/a","types":",fT","args":[0.5,true]}
+//
+// Numbers are JSON numbers, except for NaN and infinite floats which are
+// strings. Blobs are base64 strings like in encoding/json and timetags, RGBA
+// colors and MIDI messages are hex strings as in the text format. Arrays are
+// JSON arrays, in the typed form with the type "[". A Bundle is encoded as:
+//
+//	{"timetag":"0x0000000000000001","messages":[...],"bundles":[...]}
+
+type jsonMessage struct {
+	Address string  `json:"address"`
+	Types   *string `json:"types,omitempty"`
+	Args    []any   `json:"args"`
+}
+
+type jsonArgument struct {
+	Type  string `json:"type"`
+	Value any    `json:"value,omitempty"`
+}
+
+type jsonBundle struct {
+	Timetag  string `json:"timetag"`
+	Name     string `json:"name,omitempty"`
+	Messages []any  `json:"messages"`
+	Bundles  []any  `json:"bundles"`
+}
+
+// rawMessage, rawArgument and rawBundle are used for decoding.
+type rawMessage struct {
+	Address string            `json:"address"`
+	Types   *string           `json:"types"`
+	Args    []json.RawMessage `json:"args"`
+}
+
+type rawArgument struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+type rawBundle struct {
+	Timetag  json.RawMessage `json:"timetag"`
+	Name     string          `json:"name"`
+	Messages []*Message      `json:"messages"`
+	Bundles  []*Bundle       `json:"bundles"`
+}
+
+// MarshalJSON encodes the Message with typed arguments.
+func (m *Message) MarshalJSON() ([]byte, error) {
+	v, err := messageJSON(m, false)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes a Message in either the typed or the compact form.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var raw rawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Address == "" {
+		return errors.New("message has no address")
+	}
+
+	var args []any
+	var err error
+	if raw.Types != nil {
+		args, err = compactArgumentsFromJSON(*raw.Types, raw.Args)
+	} else {
+		args, err = typedArgumentsFromJSON(raw.Args)
+	}
+	if err != nil {
+		return fmt.Errorf("message %s: %w", raw.Address, err)
+	}
+	m.Address = raw.Address
+	m.Arguments = args
+	return nil
+}
+
+// MarshalJSON encodes the Bundle with typed arguments in all messages.
+func (b *Bundle) MarshalJSON() ([]byte, error) {
+	v, err := bundleJSON(b, false)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes a Bundle with messages in either the typed or the
+// compact form.
+func (b *Bundle) UnmarshalJSON(data []byte) error {
+	var raw rawBundle
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Timetag == nil {
+		return errors.New("bundle has no timetag")
+	}
+	tt, err := timetagFromJSON(raw.Timetag)
+	if err != nil {
+		return err
+	}
+	b.Timetag = tt
+	b.Name = raw.Name
+	b.Messages = raw.Messages
+	b.Bundles = raw.Bundles
+	if b.Messages == nil {
+		b.Messages = make([]*Message, 0)
+	}
+	if b.Bundles == nil {
+		b.Bundles = make([]*Bundle, 0)
+	}
+	return nil
+}
+
+// MarshalCompactJSON encodes a Message or Bundle using the compact form for
+// all messages.
+func MarshalCompactJSON(pkg Package) ([]byte, error) {
+	var v any
+	var err error
+	switch p := pkg.(type) {
+	case *Message:
+		v, err = messageJSON(p, true)
+	case *Bundle:
+		v, err = bundleJSON(p, true)
+	default:
+		return nil, fmt.Errorf("unknown package type (%v)", pkg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalPackageJSON decodes a Message or a Bundle depending on the content
+// of data.
+func UnmarshalPackageJSON(data []byte) (Package, error) {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, err
+	}
+	if _, ok := keys["address"]; ok {
+		msg := &Message{}
+		return msg, msg.UnmarshalJSON(data)
+	}
+	if _, ok := keys["timetag"]; ok {
+		bundle := &Bundle{}
+		return bundle, bundle.UnmarshalJSON(data)
+	}
+	return nil, errors.New("json is neither a message nor a bundle")
+}
+
+func messageJSON(m *Message, compact bool) (*jsonMessage, error) {
+	res := &jsonMessage{Address: m.Address}
+	var err error
+	if compact {
+		types := m.TypeTags()
+		res.Types = &types
+		res.Args, err = compactArgumentsJSON(m.Arguments)
+	} else {
+		res.Args, err = typedArgumentsJSON(m.Arguments)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("message %s: %w", m.Address, err)
+	}
+	return res, nil
+}
+
+func bundleJSON(b *Bundle, compact bool) (*jsonBundle, error) {
+	res := &jsonBundle{
+		Timetag:  formatTimetag(b.Timetag),
+		Name:     b.Name,
+		Messages: make([]any, 0, len(b.Messages)),
+		Bundles:  make([]any, 0, len(b.Bundles)),
+	}
+	for _, m := range b.Messages {
+		v, err := messageJSON(m, compact)
+		if err != nil {
+			return nil, err
+		}
+		res.Messages = append(res.Messages, v)
+	}
+	for _, nb := range b.Bundles {
+		v, err := bundleJSON(nb, compact)
+		if err != nil {
+			return nil, err
+		}
+		res.Bundles = append(res.Bundles, v)
+	}
+	return res, nil
+}
+
+func typedArgumentsJSON(args []any) ([]any, error) {
+	res := make([]any, 0, len(args))
+	for _, a := range args {
+		if arr, ok := a.([]any); ok {
+			inner, err := typedArgumentsJSON(arr)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, jsonArgument{Type: string(TypeTagArrayStart), Value: inner})
+			continue
+		}
+		tt, ok := typeTagOf(a)
+		if !ok {
+			return nil, fmt.Errorf("cannot encode argument of type %T", a)
+		}
+		arg := jsonArgument{Type: string(tt)}
+		switch tt {
+		case TypeTagTrue, TypeTagFalse, TypeTagNil, TypeTagInfinite:
+		default:
+			arg.Value = valueJSON(a)
+		}
+		res = append(res, arg)
+	}
+	return res, nil
+}
+
+func compactArgumentsJSON(args []any) ([]any, error) {
+	res := make([]any, 0, len(args))
+	for _, a := range args {
+		if arr, ok := a.([]any); ok {
+			inner, err := compactArgumentsJSON(arr)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, inner)
+			continue
+		}
+		if _, ok := typeTagOf(a); !ok {
+			return nil, fmt.Errorf("cannot encode argument of type %T", a)
+		}
+		res = append(res, valueJSON(a))
+	}
+	return res, nil
+}
+
+// valueJSON returns the value to encode for a single argument.
+func valueJSON(a any) any {
+	switch v := a.(type) {
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return formatFloat(float64(v), 32)
+		}
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return formatFloat(v, 64)
+		}
+	case Symbol:
+		return string(v)
+	case Char:
+		return string(rune(v))
+	case Timetag, color.RGBA, MIDI:
+		str, _ := formatValue(v)
+		return str
+	case Infinitum:
+		return nil
+	}
+	return a
+}
+
+func typedArgumentsFromJSON(raw []json.RawMessage) ([]any, error) {
+	res := make([]any, 0, len(raw))
+	for i, r := range raw {
+		var arg rawArgument
+		if err := json.Unmarshal(r, &arg); err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i, err)
+		}
+		if len(arg.Type) != 1 {
+			return nil, fmt.Errorf("argument %d: invalid type %q", i, arg.Type)
+		}
+		tt := typeTag(arg.Type[0])
+		if tt == TypeTagArrayStart {
+			var inner []json.RawMessage
+			if err := json.Unmarshal(arg.Value, &inner); err != nil {
+				return nil, fmt.Errorf("argument %d: %w", i, err)
+			}
+			arr, err := typedArgumentsFromJSON(inner)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, arr)
+			continue
+		}
+		v, err := valueFromJSON(tt, arg.Value)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d: %w", i, err)
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+func compactArgumentsFromJSON(types string, raw []json.RawMessage) ([]any, error) {
+	if len(types) == 0 || types[0] != ',' {
+		return nil, errors.New("typetag format error")
+	}
+	args, rest, err := compactArrayFromJSON(types[1:], raw, false)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, errors.New("typetag array end without start")
+	}
+	return args, nil
+}
+
+// compactArrayFromJSON decodes the values in raw using the type tags in types
+// until the end of the array, returning the type tags after it.
+func compactArrayFromJSON(types string, raw []json.RawMessage, nested bool) (args []any, rest string, err error) {
+	args = make([]any, 0, len(raw))
+	i := 0
+	for len(types) > 0 {
+		tt := typeTag(types[0])
+		types = types[1:]
+		if tt == TypeTagArrayStop {
+			if !nested {
+				return nil, "", errors.New("typetag array end without start")
+			}
+			nested = false
+			break
+		}
+		if i >= len(raw) {
+			return nil, "", fmt.Errorf("missing value for type tag '%c'", tt)
+		}
+		r := raw[i]
+		i++
+		if tt == TypeTagArrayStart {
+			var inner []json.RawMessage
+			if err := json.Unmarshal(r, &inner); err != nil {
+				return nil, "", fmt.Errorf("argument %d: %w", i-1, err)
+			}
+			var arr []any
+			if arr, types, err = compactArrayFromJSON(types, inner, true); err != nil {
+				return nil, "", err
+			}
+			args = append(args, arr)
+			continue
+		}
+		v, err := valueFromJSON(tt, r)
+		if err != nil {
+			return nil, "", fmt.Errorf("argument %d: %w", i-1, err)
+		}
+		args = append(args, v)
+	}
+	if nested {
+		return nil, "", errors.New("typetag array start without end")
+	}
+	if i != len(raw) {
+		return nil, "", fmt.Errorf("%d values for %d type tags", len(raw), i)
+	}
+	return args, types, nil
+}
+
+// valueFromJSON decodes the value of a single argument with the type tag tt.
+func valueFromJSON(tt typeTag, raw json.RawMessage) (any, error) {
+	switch tt {
+	case TypeTagTrue:
+		return true, nil
+	case TypeTagFalse:
+		return false, nil
+	case TypeTagNil:
+		return nil, nil
+	case TypeTagInfinite:
+		return Infinitum{}, nil
+	case TypeTagBlob:
+		var b []byte
+		err := json.Unmarshal(raw, &b)
+		return b, err
+	case TypeTagString, TypeTagStringAlternate, TypeTagChar:
+		var str string
+		if err := json.Unmarshal(raw, &str); err != nil {
+			return nil, err
+		}
+		if tt == TypeTagStringAlternate {
+			return Symbol(str), nil
+		}
+		if tt == TypeTagChar {
+			r := []rune(str)
+			if len(r) != 1 {
+				return nil, fmt.Errorf("char %q must be a single character", str)
+			}
+			return Char(r[0]), nil
+		}
+		return str, nil
+	}
+
+	// Numbers are parsed from their JSON text and the remaining types from
+	// strings, using the text format.
+	str := string(bytes.TrimSpace(raw))
+	if len(raw) > 0 && raw[0] == '"' {
+		if err := json.Unmarshal(raw, &str); err != nil {
+			return nil, err
+		}
+	}
+	return parseValue(tt, str)
+}
+
+func timetagFromJSON(raw json.RawMessage) (Timetag, error) {
+	str := string(raw)
+	if len(raw) > 0 && raw[0] == '"' {
+		if err := json.Unmarshal(raw, &str); err != nil {
+			return 0, err
+		}
+	}
+	return parseTimetag(str)
+}
@@ -0,0 +1,138 @@
+package gosc
+
+import (
+	"encoding/json"
+	"image/color"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMessage_MarshalJSON(t *testing.T) {
+	msg := &Message{Address: "/test", Arguments: []any{float32(0.5), int32(3), true, []any{"a"}}}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		t.Errorf("expected no error but got: %v", err)
+	}
+	expected := `{"address":"/test","args":[{"type":"f","value":0.5},{"type":"i","value":3},{"type":"T"},` +
+		`{"type":"[","value":[{"type":"s","value":"a"}]}]}`
+	if string(data) != expected {
+		t.Errorf("expected %s but got: %s", expected, data)
+	}
+}
+
+func TestMessage_UnmarshalJSON(t *testing.T) {
+	allTypes := &Message{
+		Address: "/test",
+		Arguments: []any{
+			int32(1), float32(0.5), "a", []byte{1, 2}, int64(1 << 40), Timetag(1), float64(0.25),
+			Symbol("s"), Char('c'), color.RGBA{R: 1, G: 2, B: 3, A: 4}, MIDI{Status: 0x90},
+			true, false, nil, Infinitum{}, []any{int32(2), []any{float32(1)}},
+		},
+	}
+	t.Run("typedRoundTrip", func(t *testing.T) {
+		data, err := json.Marshal(allTypes)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		res := &Message{}
+		if err := json.Unmarshal(data, res); err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+		if !reflect.DeepEqual(res, allTypes) {
+			t.Errorf("expected %v but got: %v", allTypes, res)
+		}
+	})
+	t.Run("compactRoundTrip", func(t *testing.T) {
+		data, err := MarshalCompactJSON(allTypes)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		res := &Message{}
+		if err := json.Unmarshal(data, res); err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+		if !reflect.DeepEqual(res, allTypes) {
+			t.Errorf("expected %v but got: %v", allTypes, res)
+		}
+	})
+	t.Run("compactForm", func(t *testing.T) {
+		res := &Message{}
+		err := json.Unmarshal([]byte(`{"address":"/a","types":",fh","args":[0.5,3]}`), res)
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+		expected := []any{float32(0.5), int64(3)}
+		if !reflect.DeepEqual(res.Arguments, expected) {
+			t.Errorf("expected %v but got: %v", expected, res.Arguments)
+		}
+	})
+	t.Run("nonFiniteFloat", func(t *testing.T) {
+		data, err := json.Marshal(&Message{Address: "/a", Arguments: []any{math.Inf(1)}})
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		res := &Message{}
+		if err := json.Unmarshal(data, res); err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+		if v, _ := res.Arguments[0].(float64); !math.IsInf(v, 1) {
+			t.Errorf("expected +Inf but got: %v", res.Arguments[0])
+		}
+	})
+	t.Run("valueCountMismatch", func(t *testing.T) {
+		err := json.Unmarshal([]byte(`{"address":"/a","types":",ff","args":[0.5]}`), &Message{})
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("wrongValueType", func(t *testing.T) {
+		err := json.Unmarshal([]byte(`{"address":"/a","args":[{"type":"i","value":"a"}]}`), &Message{})
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("unknownType", func(t *testing.T) {
+		err := json.Unmarshal([]byte(`{"address":"/a","args":[{"type":"x","value":1}]}`), &Message{})
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+}
+
+func TestBundle_MarshalJSON(t *testing.T) {
+	b := &Bundle{
+		Timetag:  Immediately,
+		Messages: []*Message{{Address: "/a", Arguments: []any{int32(1)}}},
+		Bundles:  []*Bundle{{Timetag: 2, Messages: []*Message{}, Bundles: []*Bundle{}}},
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	res, err := UnmarshalPackageJSON(data)
+	if err != nil {
+		t.Errorf("expected no error but got: %v", err)
+	}
+	if !reflect.DeepEqual(res, b) {
+		t.Errorf("expected %v but got: %v", b, res)
+	}
+}
+
+func TestUnmarshalPackageJSON(t *testing.T) {
+	t.Run("message", func(t *testing.T) {
+		pkg, err := UnmarshalPackageJSON([]byte(`{"address":"/a","args":[]}`))
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+		if pkg.GetType() != PackageTypeMessage {
+			t.Errorf("expected message but got: %s", pkg.GetType())
+		}
+	})
+	t.Run("unknown", func(t *testing.T) {
+		_, err := UnmarshalPackageJSON([]byte(`{"foo":1}`))
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+}
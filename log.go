@@ -0,0 +1,45 @@
+package gosc
+
+import (
+	"context"
+	"log/slog"
+)
+
+// discardHandler is a slog.Handler dropping all records, used when no logger
+// is configured.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+var discardLogger = slog.New(discardHandler{})
+
+// loggerOrDiscard returns l, or a logger dropping all records if nil.
+func loggerOrDiscard(l *slog.Logger) *slog.Logger {
+	if l == nil {
+		return discardLogger
+	}
+	return l
+}
+
+// packageAttrs returns the attributes describing pkg in log records, the
+// address and type tags of messages and the timetag and number of elements
+// of bundles. The encoded size is added when debug logging is enabled.
+func packageAttrs(logger *slog.Logger, pkg Package) []any {
+	var attrs []any
+	switch v := pkg.(type) {
+	case *Message:
+		attrs = append(attrs, "address", v.Address, "types", v.TypeTags())
+	case *Bundle:
+		attrs = append(attrs, "timetag", formatTimetag(v.Timetag),
+			"elements", len(v.Messages)+len(v.Bundles))
+	}
+	if logger.Enabled(context.Background(), slog.LevelDebug) {
+		if size, err := encodedSize(pkg); err == nil {
+			attrs = append(attrs, "size", size)
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,89 @@
+package gosc
+
+import (
+	"bytes"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes of log records.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func Test_packageAttrs(t *testing.T) {
+	msg := &Message{Address: "/test", Arguments: []any{int32(1)}}
+	t.Run("info", func(t *testing.T) {
+		logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+		attrs := packageAttrs(logger, msg)
+		if len(attrs) != 4 || attrs[1] != "/test" || attrs[3] != ",i" {
+			t.Errorf("expected address and types but got: %v", attrs)
+		}
+	})
+	t.Run("debug", func(t *testing.T) {
+		logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		attrs := packageAttrs(logger, msg)
+		if len(attrs) != 6 || attrs[5] != 16 {
+			t.Errorf("expected size of 16 but got: %v", attrs)
+		}
+	})
+	t.Run("bundle", func(t *testing.T) {
+		attrs := packageAttrs(discardLogger, &Bundle{Timetag: Immediately, Messages: []*Message{msg}})
+		if len(attrs) != 4 || attrs[3] != 1 {
+			t.Errorf("expected timetag and elements but got: %v", attrs)
+		}
+	})
+}
+
+func TestServerOptions_Logger(t *testing.T) {
+	trans, _ := NewUDPListen("127.0.0.1:0", 0)
+	defer trans.(*transportPacket).Close()
+	logs := &syncBuffer{}
+	srv := NewServer(&ServerOptions{
+		Logger: slog.New(slog.NewTextHandler(logs, nil)),
+	})
+	mux := NewMux(nil)
+	mux.HandleMessageFunc("/hello", func(w *ResponseWriter, msg *Message) {
+		_ = w.Send(&Message{Address: msg.Address})
+	})
+	go func() {
+		_ = srv.Serve(trans, mux)
+	}()
+
+	cli, _ := NewUDPTransport(trans.(*transportPacket).conn.LocalAddr().String(), 0)
+	defer cli.(*transportPacket).Close()
+	_, _ = cli.(*transportPacket).conn.(net.Conn).Write([]byte("garbage"))
+	_ = cli.Send(&Message{Address: "/unknown", Arguments: []any{"a"}}, nil)
+	_ = cli.Send(&Message{Address: "/hello"}, nil)
+	_, _, _ = cli.Receive()
+	_ = srv.Shutdown()
+
+	out := logs.String()
+	for _, expected := range []string{
+		`msg="dropped package"`,
+		`msg="unrouted message"`,
+		`address=/unknown types=,s`,
+		`msg="server shutting down"`,
+		`msg="server stopped"`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected log to contain %s but got:\n%s", expected, out)
+		}
+	}
+}
@@ -0,0 +1,500 @@
+package gosc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// argField describes how a struct field maps to a Message argument.
+type argField struct {
+	name     string
+	index    int
+	pos      int
+	tag      typeTag
+	optional bool
+}
+
+// argFieldCache maps reflect.Type to the []argField of the struct type.
+var argFieldCache sync.Map
+
+// typeTagNames maps the type names usable in struct tags to the typeTag used
+// when marshaling the field.
+var typeTagNames = map[string]typeTag{
+	"int32":   TypeTagInt32,
+	"float32": TypeTagFloat32,
+	"string":  TypeTagString,
+	"blob":    TypeTagBlob,
+	"timetag": TypeTagTimetag,
+	"bool":    TypeTagTrue,
+	"int64":   TypeTagBigInt,
+	"float64": TypeTagDouble,
+	"symbol":  TypeTagStringAlternate,
+}
+
+var timetagType = reflect.TypeOf(Timetag(0))
+
+// MarshalArgs returns the Message arguments for the struct v, or pointer to
+// struct, with one argument for each exported field.
+//
+// The position and encoding of a field can be customized with the "osc" key in
+// the field's tag. The first option is the argument position, when omitted the
+// position after the previous field is used. The following options are a type
+// name to convert the field to (int32, int64, float32, float64, string, symbol,
+// blob, timetag or bool) and "optional". Trailing optional fields with zero
+// value are left out when marshaling and missing optional arguments are
+// ignored when unmarshaling. A field with the tag "-" is always ignored.
+//
+//	type Channel struct {
+//		Name   string  `osc:"0"`
+//		Fader  float64 `osc:",float32"`
+//		Mute   int     `osc:",int32,optional"`
+//		Ignore string  `osc:"-"`
+//	}
+//
+// Integers and floats are marshaled as int32 and float32 by default. Slices,
+// arrays and nested structs are marshaled as OSC arrays, except for []byte
+// which is marshaled as a blob.
+func MarshalArgs(v any) ([]any, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, errors.New("cannot marshal nil pointer")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot marshal %T, must be a struct", v)
+	}
+	return marshalStruct(rv)
+}
+
+// UnmarshalArgs stores the arguments of msg in the struct pointed to by v. See
+// MarshalArgs for how fields map to arguments. Numeric arguments are converted
+// to the type of the field if the value fits.
+func UnmarshalArgs(msg *Message, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot unmarshal into %T, must be a non-nil pointer to a struct", v)
+	}
+	return unmarshalStruct(msg.Arguments, rv.Elem())
+}
+
+func marshalStruct(v reflect.Value) ([]any, error) {
+	fields, err := argFields(v.Type())
+	if err != nil {
+		return nil, err
+	}
+	n := len(fields)
+	for n > 0 && fields[n-1].optional && v.Field(fields[n-1].index).IsZero() {
+		n--
+	}
+
+	res := make([]any, 0, n)
+	for _, f := range fields[:n] {
+		arg, err := marshalValue(v.Field(f.index), f.tag)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.name, err)
+		}
+		res = append(res, arg)
+	}
+	return res, nil
+}
+
+func marshalValue(v reflect.Value, tag typeTag) (any, error) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return marshalIndirect(v, tag)
+	case reflect.Struct:
+		return marshalStruct(v)
+	case reflect.Slice, reflect.Array:
+		return marshalSequence(v, tag)
+	}
+
+	if tag == 0 {
+		tag = defaultTypeTag(v)
+	}
+	switch tag {
+	case TypeTagInt32:
+		return toInt32(v)
+	case TypeTagFloat32:
+		return toFloat32(v)
+	case TypeTagBigInt:
+		return toInt64(v)
+	case TypeTagDouble:
+		return toFloat64(v)
+	}
+	return marshalScalar(v, tag)
+}
+
+// marshalIndirect marshals the value pointed to by the pointer or interface v.
+func marshalIndirect(v reflect.Value, tag typeTag) (any, error) {
+	if v.IsNil() {
+		return nil, errors.New("cannot marshal nil value")
+	}
+	if v.Kind() == reflect.Interface && tag == 0 {
+		return v.Elem().Interface(), nil
+	}
+	return marshalValue(v.Elem(), tag)
+}
+
+// marshalSequence marshals the slice or array v as a blob if it holds bytes,
+// otherwise as an OSC array.
+func marshalSequence(v reflect.Value, tag typeTag) (any, error) {
+	if v.Type().Elem().Kind() == reflect.Uint8 && (tag == 0 || tag == TypeTagBlob) {
+		blob := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(blob), v)
+		return blob, nil
+	}
+	arr := make([]any, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		e, err := marshalValue(v.Index(i), tag)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, e)
+	}
+	return arr, nil
+}
+
+// marshalScalar marshals v as a string, symbol, timetag or bool.
+func marshalScalar(v reflect.Value, tag typeTag) (any, error) {
+	switch tag {
+	case TypeTagString:
+		if v.Kind() == reflect.String {
+			return v.String(), nil
+		}
+	case TypeTagStringAlternate:
+		if v.Kind() == reflect.String {
+			return Symbol(v.String()), nil
+		}
+	case TypeTagTimetag:
+		switch v.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return Timetag(v.Uint()), nil
+		}
+	case TypeTagTrue:
+		if v.Kind() == reflect.Bool {
+			return v.Bool(), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot marshal %v as '%c'", v.Type(), tag)
+}
+
+func defaultTypeTag(v reflect.Value) typeTag {
+	if v.Type() == timetagType {
+		return TypeTagTimetag
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return TypeTagInt32
+	case reflect.Float32, reflect.Float64:
+		return TypeTagFloat32
+	case reflect.String:
+		return TypeTagString
+	case reflect.Bool:
+		return TypeTagTrue
+	}
+	return 0
+}
+
+func toInt32(v reflect.Value) (any, error) {
+	var i int64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i = v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt32 {
+			return nil, fmt.Errorf("value %d overflows int32", v.Uint())
+		}
+		i = int64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		f, err := floatToInt(v.Float())
+		if err != nil {
+			return nil, err
+		}
+		i = f
+	default:
+		return nil, fmt.Errorf("cannot marshal %v as int32", v.Type())
+	}
+	if i < math.MinInt32 || i > math.MaxInt32 {
+		return nil, fmt.Errorf("value %d overflows int32", i)
+	}
+	return int32(i), nil
+}
+
+func toInt64(v reflect.Value) (any, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("value %d overflows int64", v.Uint())
+		}
+		return int64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return floatToInt(v.Float())
+	}
+	return nil, fmt.Errorf("cannot marshal %v as int64", v.Type())
+}
+
+// floatToInt converts f to an int64, returning error for NaN, infinities and
+// values out of range.
+func floatToInt(f float64) (int64, error) {
+	// float64(math.MaxInt64) rounds up to 2^63, which does not fit.
+	if math.IsNaN(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("value %v overflows int64", f)
+	}
+	return int64(f), nil
+}
+
+func toFloat64(v reflect.Value) (any, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	}
+	return nil, fmt.Errorf("cannot marshal %v as float64", v.Type())
+}
+
+func toFloat32(v reflect.Value) (any, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float32(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float32(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return float32(v.Float()), nil
+	}
+	return nil, fmt.Errorf("cannot marshal %v as float32", v.Type())
+}
+
+func unmarshalStruct(args []any, v reflect.Value) error {
+	fields, err := argFields(v.Type())
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if f.pos >= len(args) {
+			if f.optional {
+				continue
+			}
+			return fmt.Errorf("missing argument %d for field %s", f.pos, f.name)
+		}
+		if err := unmarshalValue(args[f.pos], v.Field(f.index)); err != nil {
+			return fmt.Errorf("field %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+func unmarshalValue(arg any, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return unmarshalValue(arg, v.Elem())
+	case reflect.Interface:
+		if v.NumMethod() == 0 {
+			if arg == nil {
+				v.Set(reflect.Zero(v.Type()))
+			} else {
+				v.Set(reflect.ValueOf(arg))
+			}
+			return nil
+		}
+	}
+
+	switch a := arg.(type) {
+	case int32, int64, float32, float64:
+		return unmarshalNumber(a, v)
+	case []byte:
+		return unmarshalBlob(a, v)
+	case []any:
+		if v.Kind() == reflect.Struct {
+			return unmarshalStruct(a, v)
+		}
+		return setSequence(v, len(a), func(i int, e reflect.Value) error {
+			return unmarshalValue(a[i], e)
+		})
+	}
+	if unmarshalScalar(arg, v) {
+		return nil
+	}
+	return fmt.Errorf("cannot unmarshal %T into %v", arg, v.Type())
+}
+
+// unmarshalNumber sets the numeric value v to the int32, int64, float32 or
+// float64 arg.
+func unmarshalNumber(arg any, v reflect.Value) error {
+	switch a := arg.(type) {
+	case int32:
+		return setInt(v, int64(a))
+	case int64:
+		return setInt(v, a)
+	case float32:
+		return setFloat(v, float64(a))
+	default:
+		return setFloat(v, a.(float64))
+	}
+}
+
+// unmarshalBlob sets the byte slice v to a copy of blob, or the elements of
+// another numeric slice or array.
+func unmarshalBlob(blob []byte, v reflect.Value) error {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		v.SetBytes(append([]byte(nil), blob...))
+		return nil
+	}
+	return setSequence(v, len(blob), func(i int, e reflect.Value) error {
+		return setInt(e, int64(blob[i]))
+	})
+}
+
+// unmarshalScalar sets v to the timetag, string, symbol or bool arg. It
+// returns false if arg is of another type or does not match the kind of v.
+func unmarshalScalar(arg any, v reflect.Value) bool {
+	switch a := arg.(type) {
+	case Timetag:
+		if v.Type() == timetagType {
+			v.SetUint(uint64(a))
+			return true
+		}
+	case string:
+		if v.Kind() == reflect.String {
+			v.SetString(a)
+			return true
+		}
+	case Symbol:
+		if v.Kind() == reflect.String {
+			v.SetString(string(a))
+			return true
+		}
+	case bool:
+		if v.Kind() == reflect.Bool {
+			v.SetBool(a)
+			return true
+		}
+	}
+	return false
+}
+
+// setInt sets the numeric value v to i, returning error if v is not numeric or
+// i does not fit.
+func setInt(v reflect.Value, i int64) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.OverflowInt(i) {
+			return fmt.Errorf("value %d overflows %v", i, v.Type())
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if i < 0 || v.OverflowUint(uint64(i)) {
+			return fmt.Errorf("value %d overflows %v", i, v.Type())
+		}
+		v.SetUint(uint64(i))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(float64(i))
+	default:
+		return fmt.Errorf("cannot unmarshal number into %v", v.Type())
+	}
+	return nil
+}
+
+// setFloat sets the numeric value v to f, returning error if v is not numeric
+// or f does not fit.
+func setFloat(v reflect.Value, f float64) error {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(f)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := floatToInt(f)
+		if err != nil {
+			return fmt.Errorf("value %v overflows %v", f, v.Type())
+		}
+		return setInt(v, i)
+	}
+	return fmt.Errorf("cannot unmarshal number into %v", v.Type())
+}
+
+// setSequence fills the slice or array v with n elements using set.
+func setSequence(v reflect.Value, n int, set func(i int, e reflect.Value) error) error {
+	switch v.Kind() {
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+	case reflect.Array:
+		if n > v.Len() {
+			return fmt.Errorf("%d elements do not fit in %v", n, v.Type())
+		}
+	default:
+		return fmt.Errorf("cannot unmarshal sequence into %v", v.Type())
+	}
+	for i := 0; i < n; i++ {
+		if err := set(i, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// argFields returns the argument fields of the struct type t sorted by
+// position.
+func argFields(t reflect.Type) ([]argField, error) {
+	if f, ok := argFieldCache.Load(t); ok {
+		return f.([]argField), nil
+	}
+
+	fields := make([]argField, 0, t.NumField())
+	next := 0
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("osc")
+		if !sf.IsExported() || tag == "-" {
+			continue
+		}
+		f := argField{name: sf.Name, index: i, pos: next}
+		opts := strings.Split(tag, ",")
+		if opts[0] != "" {
+			pos, err := strconv.Atoi(opts[0])
+			if err != nil || pos < 0 {
+				return nil, fmt.Errorf("field %s: invalid argument position %q", sf.Name, opts[0])
+			}
+			f.pos = pos
+		}
+		for _, opt := range opts[1:] {
+			if opt == "optional" {
+				f.optional = true
+			} else if tt, ok := typeTagNames[opt]; ok {
+				f.tag = tt
+			} else if opt != "" {
+				return nil, fmt.Errorf("field %s: unknown option %q", sf.Name, opt)
+			}
+		}
+		next = f.pos + 1
+		fields = append(fields, f)
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].pos < fields[j].pos
+	})
+	for i, f := range fields {
+		if f.pos != i {
+			return nil, fmt.Errorf("%v: fields do not cover argument %d exactly once", t, i)
+		}
+	}
+
+	argFieldCache.Store(t, fields)
+	return fields, nil
+}
@@ -0,0 +1,156 @@
+package gosc
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+type testChannel struct {
+	Name   string  `osc:"0"`
+	Fader  float64 `osc:",float32"`
+	Pan    int
+	Ignore string `osc:"-"`
+	Sends  []float32
+	EQ     testEQ
+	Mute   int `osc:",int32,optional"`
+}
+
+type testEQ struct {
+	Gain float32
+	Freq float32
+}
+
+func TestMarshalArgs(t *testing.T) {
+	t.Run("struct", func(t *testing.T) {
+		args, err := MarshalArgs(&testChannel{
+			Name:  "Kick",
+			Fader: 0.75,
+			Pan:   -10,
+			Sends: []float32{0.5, 0.25},
+			EQ:    testEQ{Gain: 3, Freq: 100},
+		})
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+		expected := []any{
+			"Kick", float32(0.75), int32(-10),
+			[]any{float32(0.5), float32(0.25)},
+			[]any{float32(3), float32(100)},
+		}
+		if !reflect.DeepEqual(args, expected) {
+			t.Errorf("expected arguments %v but got: %v", expected, args)
+		}
+	})
+	t.Run("optionalSet", func(t *testing.T) {
+		args, _ := MarshalArgs(testChannel{Mute: 1})
+		if len(args) != 6 {
+			t.Errorf("expected 6 arguments but got: %d", len(args))
+		}
+	})
+	t.Run("notStruct", func(t *testing.T) {
+		_, err := MarshalArgs(1)
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("overflow", func(t *testing.T) {
+		_, err := MarshalArgs(struct{ A int64 }{A: 1 << 40})
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("invalidFloat", func(t *testing.T) {
+		for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1), 1e19} {
+			if _, err := MarshalArgs(struct {
+				A float64 `osc:",int64"`
+			}{A: f}); err == nil {
+				t.Errorf("expected error for %v but none given", f)
+			}
+			if _, err := MarshalArgs(struct {
+				A float64 `osc:",int32"`
+			}{A: f}); err == nil {
+				t.Errorf("expected error for %v but none given", f)
+			}
+		}
+	})
+	t.Run("duplicatePosition", func(t *testing.T) {
+		_, err := MarshalArgs(struct {
+			A int32 `osc:"0"`
+			B int32 `osc:"0"`
+		}{})
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("unknownOption", func(t *testing.T) {
+		_, err := MarshalArgs(struct {
+			A int32 `osc:",unknown"`
+		}{})
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+}
+
+func TestUnmarshalArgs(t *testing.T) {
+	t.Run("struct", func(t *testing.T) {
+		var ch testChannel
+		err := UnmarshalArgs(&Message{
+			Address: "/ch/1",
+			Arguments: []any{
+				"Kick", float32(0.75), int32(-10),
+				[]any{float32(0.5), float32(0.25)},
+				[]any{float32(3), float32(100)},
+			},
+		}, &ch)
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+		expected := testChannel{
+			Name:  "Kick",
+			Fader: 0.75,
+			Pan:   -10,
+			Sends: []float32{0.5, 0.25},
+			EQ:    testEQ{Gain: 3, Freq: 100},
+		}
+		if !reflect.DeepEqual(ch, expected) {
+			t.Errorf("expected %+v but got: %+v", expected, ch)
+		}
+	})
+	t.Run("missingArgument", func(t *testing.T) {
+		var ch testChannel
+		err := UnmarshalArgs(&Message{Arguments: []any{"Kick"}}, &ch)
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("wrongType", func(t *testing.T) {
+		var v struct{ A string }
+		err := UnmarshalArgs(&Message{Arguments: []any{int32(1)}}, &v)
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("overflow", func(t *testing.T) {
+		var v struct{ A int8 }
+		err := UnmarshalArgs(&Message{Arguments: []any{int32(1000)}}, &v)
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("invalidFloat", func(t *testing.T) {
+		for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1), math.MaxInt64} {
+			var v struct{ A int64 }
+			if err := UnmarshalArgs(&Message{Arguments: []any{f}}, &v); err == nil {
+				t.Errorf("expected error for %v but got: %d", f, v.A)
+			}
+		}
+	})
+	t.Run("notPointer", func(t *testing.T) {
+		err := UnmarshalArgs(&Message{}, testChannel{})
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+}
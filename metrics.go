@@ -0,0 +1,76 @@
+package gosc
+
+import (
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics receives instrumentation events from servers, their transports and
+// Mux, e.g. to count packages and measure handler latency. Implementations
+// must be safe for concurrent use. See the oscprom package for an
+// implementation exposing the metrics to Prometheus.
+type Metrics interface {
+	// PackageReceived is called by the transports for every packet read,
+	// with its size in bytes, before it is decoded.
+	PackageReceived(src net.Addr, size int)
+	// PackageDropped is called by the server when a packet is dropped since
+	// it could not be received or decoded.
+	PackageDropped(src net.Addr, err error)
+	// MessageHandled is called by Mux when the handler registered for
+	// address has returned, with the time it took. Bundles are reported
+	// with the address "#bundle".
+	MessageHandled(address string, src net.Addr, duration time.Duration)
+	// MessageUnrouted is called by Mux for messages without a handler.
+	MessageUnrouted(address string, src net.Addr)
+	// PackageSent is called when a response has been sent, err is set if
+	// sending failed.
+	PackageSent(dst net.Addr, err error)
+}
+
+// nopMetrics is the Metrics used when none is configured.
+type nopMetrics struct{}
+
+func (nopMetrics) PackageReceived(net.Addr, int)                  {}
+func (nopMetrics) PackageDropped(net.Addr, error)                 {}
+func (nopMetrics) MessageHandled(string, net.Addr, time.Duration) {}
+func (nopMetrics) MessageUnrouted(string, net.Addr)               {}
+func (nopMetrics) PackageSent(net.Addr, error)                    {}
+
+// metricsOrNop returns m, or a Metrics ignoring all events if nil.
+func metricsOrNop(m Metrics) Metrics {
+	if m == nil {
+		return nopMetrics{}
+	}
+	return m
+}
+
+// transportMetrics is embedded by the transports to report received packets
+// to the Metrics of the server using them. The Metrics is set by the server
+// while the transport may already be receiving.
+type transportMetrics struct {
+	metrics atomic.Value
+}
+
+// metricsHolder wraps Metrics so that different implementations can be
+// stored in the same atomic.Value.
+type metricsHolder struct {
+	Metrics
+}
+
+func (t *transportMetrics) setMetrics(m Metrics) {
+	t.metrics.Store(metricsHolder{m})
+}
+
+func (t *transportMetrics) packageReceived(src net.Addr, size int) {
+	if h, ok := t.metrics.Load().(metricsHolder); ok {
+		h.PackageReceived(src, size)
+	}
+}
+
+// setTransportMetrics makes the built-in transports report to m.
+func setTransportMetrics(trans Transport, m Metrics) {
+	if t, ok := trans.(interface{ setMetrics(Metrics) }); ok {
+		t.setMetrics(m)
+	}
+}
@@ -0,0 +1,57 @@
+package gosc
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testMetrics struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (m *testMetrics) record(event string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, event)
+}
+
+func (m *testMetrics) PackageReceived(net.Addr, int)  { m.record("received") }
+func (m *testMetrics) PackageDropped(net.Addr, error) { m.record("dropped") }
+func (m *testMetrics) MessageHandled(address string, _ net.Addr, _ time.Duration) {
+	m.record("handled " + address)
+}
+func (m *testMetrics) MessageUnrouted(address string, _ net.Addr) { m.record("unrouted " + address) }
+func (m *testMetrics) PackageSent(net.Addr, error)                { m.record("sent") }
+
+func TestServerOptions_Metrics(t *testing.T) {
+	metrics := &testMetrics{}
+	a, b := net.Pipe()
+	srv := NewServer(&ServerOptions{Metrics: metrics})
+	mux := NewMux(nil)
+	mux.HandleMessageFunc("/hello", func(w *ResponseWriter, msg *Message) {
+		_ = w.Send(&Message{Address: msg.Address})
+	})
+	go func() {
+		_ = srv.Serve(NewStreamTransport(a, FramingSizePrefix), mux)
+	}()
+	cli := NewStreamTransport(b, FramingSizePrefix)
+	_ = cli.Send(&Message{Address: "/unknown"}, nil)
+	_ = cli.Send(&Message{Address: "/hello"}, nil)
+	_, _, _ = cli.Receive()
+	_ = srv.Shutdown()
+
+	expected := []string{"received", "unrouted /unknown", "received", "sent", "handled /hello"}
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if len(metrics.events) != len(expected) {
+		t.Fatalf("expected events %v but got: %v", expected, metrics.events)
+	}
+	for i, event := range expected {
+		if metrics.events[i] != event {
+			t.Errorf("expected event %q but got: %q", event, metrics.events[i])
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package gosc
+
+import (
+	"fmt"
+	"net"
+
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// MulticastOptions is the configuration of multicast UDP transports. The zero
+// value is valid.
+type MulticastOptions struct {
+	// Interface to join the group on and to send packets from, nil lets the
+	// system choose.
+	Interface *net.Interface
+	// TTL, or hop limit for IPv6, of sent packets. Defaults to 1 which keeps
+	// the packets on the local network.
+	TTL int
+	// DisableLoopback stops sent packets from being received by listeners on
+	// the same host.
+	DisableLoopback bool
+}
+
+// NewMulticastTransport returns a UDP Transport for clients sending to the
+// multicast group at address, e.g. "239.0.0.1:9000". Responses from every
+// listener of the group are received. The opts can be nil to use the
+// defaults.
+func NewMulticastTransport(address string, bufferSize int, opts *MulticastOptions) (Transport, error) {
+	return dialMulticast(address, "", bufferSize, opts)
+}
+
+// dialMulticast returns a multicast Transport bound to the local address, if
+// not empty.
+func dialMulticast(address, localAddress string, bufferSize int, opts *MulticastOptions) (Transport, error) {
+	if opts == nil {
+		opts = &MulticastOptions{}
+	}
+	group, err := resolveMulticastAddr(address)
+	if err != nil {
+		return nil, err
+	}
+	network := "udp4"
+	if group.IP.To4() == nil {
+		network = "udp6"
+	}
+	laddr, err := resolveLocalUDPAddr(localAddress)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP(network, laddr)
+	if err != nil {
+		return nil, err
+	}
+	if err := setMulticastOptions(conn, group.IP, opts); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return &transportPacket{
+		conn:       conn,
+		bufferSize: bufferSize,
+	}, nil
+}
+
+// NewMulticastListen returns a UDP Transport for servers receiving packages
+// sent to the multicast group at address, e.g. "239.0.0.1:9000". Several
+// servers on the same host can join the same group. The opts can be nil to
+// use the defaults.
+func NewMulticastListen(address string, bufferSize int, opts *MulticastOptions) (Transport, error) {
+	if opts == nil {
+		opts = &MulticastOptions{}
+	}
+	group, err := resolveMulticastAddr(address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenMulticastUDP("udp", opts.Interface, group)
+	if err != nil {
+		return nil, err
+	}
+	return &transportPacket{
+		conn:       conn,
+		bufferSize: bufferSize,
+	}, nil
+}
+
+// isMulticastAddress reports whether address is a UDP address of a multicast
+// group.
+func isMulticastAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsMulticast()
+}
+
+func resolveMulticastAddr(address string) (*net.UDPAddr, error) {
+	addr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	if !addr.IP.IsMulticast() {
+		return nil, fmt.Errorf("%v is not a multicast address", addr.IP)
+	}
+	return addr, nil
+}
+
+func setMulticastOptions(conn *net.UDPConn, group net.IP, opts *MulticastOptions) error {
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = 1
+	}
+	if group.To4() != nil {
+		p := ipv4.NewPacketConn(conn)
+		if opts.Interface != nil {
+			if err := p.SetMulticastInterface(opts.Interface); err != nil {
+				return err
+			}
+		}
+		if err := p.SetMulticastTTL(ttl); err != nil {
+			return err
+		}
+		return p.SetMulticastLoopback(!opts.DisableLoopback)
+	}
+	p := ipv6.NewPacketConn(conn)
+	if opts.Interface != nil {
+		if err := p.SetMulticastInterface(opts.Interface); err != nil {
+			return err
+		}
+	}
+	if err := p.SetMulticastHopLimit(ttl); err != nil {
+		return err
+	}
+	return p.SetMulticastLoopback(!opts.DisableLoopback)
+}
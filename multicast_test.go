@@ -0,0 +1,65 @@
+package gosc
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+func Test_isMulticastAddress(t *testing.T) {
+	tests := map[string]bool{
+		"239.0.0.1:9000":  true,
+		"[ff02::1]:9000":  true,
+		"127.0.0.1:9000":  false,
+		"239.0.0.1":       false,
+		"example.com:123": false,
+	}
+	for address, expected := range tests {
+		if isMulticastAddress(address) != expected {
+			t.Errorf("expected isMulticastAddress(%q) to be %v", address, expected)
+		}
+	}
+}
+
+func TestNewMulticastListen(t *testing.T) {
+	t.Run("notMulticast", func(t *testing.T) {
+		_, err := NewMulticastListen("127.0.0.1:0", 512, nil)
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("twoListeners", func(t *testing.T) {
+		first, err := NewMulticastListen("239.255.80.1:0", 512, nil)
+		if err != nil {
+			t.Skipf("multicast not supported: %v", err)
+		}
+		defer first.(*transportPacket).Close()
+		port := first.(*transportPacket).conn.LocalAddr().(*net.UDPAddr).Port
+		group := fmt.Sprintf("239.255.80.1:%d", port)
+		second, err := NewMulticastListen(group, 512, nil)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		defer second.(*transportPacket).Close()
+
+		cli, err := NewClient(group)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		defer cli.Close()
+		if err := cli.EmitMessage("/cue", int32(1)); err != nil {
+			t.Skipf("multicast not routable: %v", err)
+		}
+		for _, trans := range []Transport{first, second} {
+			_ = trans.(*transportPacket).conn.SetReadDeadline(time.Now().Add(time.Second))
+			pkg, _, err := trans.Receive()
+			if err != nil {
+				t.Skipf("multicast not received: %v", err)
+			}
+			if pkg.(*Message).Address != "/cue" {
+				t.Errorf("expected /cue but got: %v", pkg)
+			}
+		}
+	})
+}
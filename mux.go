@@ -1,6 +1,12 @@
 package gosc
 
-import "net"
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"log/slog"
+	"net"
+	"time"
+)
 
 // Mux is the default multiplex handler for messages and bundles. Returned by NewMux.
 type Mux struct {
@@ -12,10 +18,15 @@ func (m *Mux) HandlePackage(writer *ResponseWriter, pkg Package) {
 	switch x := pkg.(type) {
 	case *Message:
 		if handler, ok := m.messageHandlers[x.Address]; ok {
+			defer writer.instrument(x.Address, time.Now())
 			handler.HandleMessage(writer, x)
+		} else if writer != nil {
+			writer.log().Info("unrouted message", "src", writer.src, "address", x.Address, "types", x.TypeTags())
+			writer.metricsOrNop().MessageUnrouted(x.Address, writer.src)
 		}
 	case *Bundle:
 		if m.bundleHandler != nil {
+			defer writer.instrument("#bundle", time.Now())
 			m.bundleHandler.HandleBundle(writer, x)
 		}
 	}
@@ -29,6 +40,17 @@ func (m *Mux) HandleMessageFunc(addr string, handlerFunc MessageHandlerFunc) {
 	m.messageHandlers[addr] = handlerFunc
 }
 
+// HandleFunc registers a function with typed parameters as the handler for
+// messages on addr. See NewTypedHandler for the supported function signatures.
+func (m *Mux) HandleFunc(addr string, fn any) error {
+	handler, err := NewTypedHandler(fn)
+	if err != nil {
+		return err
+	}
+	m.messageHandlers[addr] = handler
+	return nil
+}
+
 // NewMux returns the Mux. The bundleHandler can be nil if not handling
 // bundles.
 func NewMux(bundleHandler BundleHandler) *Mux {
@@ -41,12 +63,69 @@ func NewMux(bundleHandler BundleHandler) *Mux {
 // ResponseWriter is used to send responses back to the requesting client on the
 // incoming connection.
 type ResponseWriter struct {
-	src   net.Addr
-	trans Transport
+	src     net.Addr
+	trans   Transport
+	logger  *slog.Logger
+	metrics Metrics
+}
+
+// NewResponseWriter returns a ResponseWriter sending responses to src using
+// trans. It is mainly useful for testing handlers, see the gosctest package.
+func NewResponseWriter(trans Transport, src net.Addr) *ResponseWriter {
+	return &ResponseWriter{
+		src:   src,
+		trans: trans,
+	}
 }
 
 // Send sends a Package to the client as a response using the Transport of the
 // server and the incoming connection.
 func (w *ResponseWriter) Send(pkg Package) error {
-	return w.trans.Send(pkg, w.src)
+	err := w.trans.Send(pkg, w.src)
+	w.metricsOrNop().PackageSent(w.src, err)
+	if err != nil {
+		w.log().Warn("send failed", append([]any{"dst", w.src, "error", err}, packageAttrs(w.log(), pkg)...)...)
+	}
+	return err
+}
+
+// TLS returns the state of the TLS connection the package was received on, or
+// nil if it was not received over TLS. The verified certificate chains of
+// clients using mutual TLS identify the peer.
+func (w *ResponseWriter) TLS() *tls.ConnectionState {
+	trans, ok := w.trans.(TLSTransport)
+	if !ok {
+		return nil
+	}
+	state, ok := trans.ConnectionState(w.src)
+	if !ok {
+		return nil
+	}
+	return &state
+}
+
+// PeerCertificate returns the leaf certificate presented by the peer of a TLS
+// connection, or nil if there is none.
+func (w *ResponseWriter) PeerCertificate() *x509.Certificate {
+	state := w.TLS()
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	return state.PeerCertificates[0]
+}
+
+func (w *ResponseWriter) log() *slog.Logger {
+	return loggerOrDiscard(w.logger)
+}
+
+func (w *ResponseWriter) metricsOrNop() Metrics {
+	return metricsOrNop(w.metrics)
+}
+
+// instrument reports the handling of address, started at start, to the
+// Metrics of the writer. The writer can be nil.
+func (w *ResponseWriter) instrument(address string, start time.Time) {
+	if w != nil && w.metrics != nil {
+		w.metrics.MessageHandled(address, w.src, time.Since(start))
+	}
 }
@@ -76,3 +76,23 @@ func TestMux_HandleMessageFunc(t *testing.T) {
 func TestMux_Send(t *testing.T) {
 	// TODO: Implement me
 }
+
+func TestMux_HandleFunc(t *testing.T) {
+	t.Run("validFunction", func(t *testing.T) {
+		mux := NewMux(nil)
+		err := mux.HandleFunc("/test", func(w *ResponseWriter, a int32) {})
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+		if len(mux.messageHandlers) != 1 {
+			t.Errorf("expected Mux messageHandlers to have 1 entry, got: %d", len(mux.messageHandlers))
+		}
+	})
+	t.Run("invalidFunction", func(t *testing.T) {
+		mux := NewMux(nil)
+		err := mux.HandleFunc("/test", func(a int32) {})
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+}
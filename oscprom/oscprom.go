@@ -0,0 +1,230 @@
+// Package oscprom provides a gosc.Metrics collecting OSC server metrics and
+// exposing them in the Prometheus text format.
+//
+//	metrics := oscprom.NewMetrics()
+//	server := gosc.NewServer(&gosc.ServerOptions{Metrics: metrics})
+//	http.Handle("/metrics", metrics)
+//
+// Sources are identified by their host, without the port, and messages by the
+// address of the handler. Unrouted messages are counted per source only, and
+// sources beyond DefaultMaxSources are counted as OtherSource, so that senders
+// can not create an unbounded number of series.
+package oscprom
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the handler duration
+// histogram buckets.
+var DefaultBuckets = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+
+// DefaultMaxSources is the default number of sources with series of their own.
+const DefaultMaxSources = 100
+
+// OtherSource is the src label of the sources beyond the maximum number.
+const OtherSource = "other"
+
+type sourceMetrics struct {
+	received     uint64
+	bytes        uint64
+	dropped      uint64
+	unrouted     uint64
+	sent         uint64
+	sendErrors   uint64
+	lastReceived time.Time
+}
+
+type handlerMetrics struct {
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+// Metrics implements gosc.Metrics and http.Handler. The zero value is not
+// valid, use NewMetrics.
+type Metrics struct {
+	mu         sync.Mutex
+	buckets    []float64
+	maxSources int
+	sources    map[string]*sourceMetrics
+	handlers   map[string]*handlerMetrics
+	now        func() time.Time
+}
+
+// NewMetrics returns Metrics using DefaultBuckets.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		buckets:    DefaultBuckets,
+		maxSources: DefaultMaxSources,
+		sources:    map[string]*sourceMetrics{},
+		handlers:   map[string]*handlerMetrics{},
+		now:        time.Now,
+	}
+}
+
+// SetMaxSources sets the number of sources with series of their own, the
+// packages of further sources are counted as OtherSource. Sources already
+// seen keep their series.
+func (m *Metrics) SetMaxSources(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxSources = n
+}
+
+// PackageReceived implements gosc.Metrics.
+func (m *Metrics) PackageReceived(src net.Addr, size int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.source(src)
+	s.received++
+	s.bytes += uint64(size)
+	s.lastReceived = m.now()
+}
+
+// PackageDropped implements gosc.Metrics.
+func (m *Metrics) PackageDropped(src net.Addr, _ error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.source(src).dropped++
+}
+
+// MessageHandled implements gosc.Metrics.
+func (m *Metrics) MessageHandled(address string, _ net.Addr, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.handlers[address]
+	if !ok {
+		h = &handlerMetrics{buckets: make([]uint64, len(m.buckets))}
+		m.handlers[address] = h
+	}
+	seconds := duration.Seconds()
+	for i, le := range m.buckets {
+		if seconds <= le {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// MessageUnrouted implements gosc.Metrics.
+func (m *Metrics) MessageUnrouted(_ string, src net.Addr) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.source(src).unrouted++
+}
+
+// PackageSent implements gosc.Metrics.
+func (m *Metrics) PackageSent(dst net.Addr, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.source(dst)
+	s.sent++
+	if err != nil {
+		s.sendErrors++
+	}
+}
+
+func (m *Metrics) source(addr net.Addr) *sourceMetrics {
+	host := ""
+	if addr != nil {
+		host = addr.String()
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+	}
+	s, ok := m.sources[host]
+	if !ok && host != OtherSource && len(m.sources) >= m.maxSources {
+		host = OtherSource
+		s, ok = m.sources[host]
+	}
+	if !ok {
+		s = &sourceMetrics{}
+		m.sources[host] = s
+	}
+	return s
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.WriteText(w)
+}
+
+// WriteText writes the metrics in the Prometheus text format to w.
+func (m *Metrics) WriteText(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sb := strings.Builder{}
+	sources := sortedKeys(m.sources)
+	counter := func(name, help string, value func(s *sourceMetrics) uint64) {
+		fmt.Fprintf(&sb, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		for _, src := range sources {
+			fmt.Fprintf(&sb, "%s{src=%s} %d\n", name, quote(src), value(m.sources[src]))
+		}
+	}
+	counter("osc_packages_received_total", "Packets received per source.",
+		func(s *sourceMetrics) uint64 { return s.received })
+	counter("osc_received_bytes_total", "Bytes received per source.",
+		func(s *sourceMetrics) uint64 { return s.bytes })
+	counter("osc_packages_dropped_total", "Packets dropped per source since they could not be received or decoded.",
+		func(s *sourceMetrics) uint64 { return s.dropped })
+	counter("osc_messages_unrouted_total", "Messages without a handler per source.",
+		func(s *sourceMetrics) uint64 { return s.unrouted })
+	counter("osc_packages_sent_total", "Responses sent per destination.",
+		func(s *sourceMetrics) uint64 { return s.sent })
+	counter("osc_send_errors_total", "Responses that could not be sent per destination.",
+		func(s *sourceMetrics) uint64 { return s.sendErrors })
+
+	name := "osc_last_received_timestamp_seconds"
+	fmt.Fprintf(&sb, "# HELP %s Unix time of the last packet received per source.\n# TYPE %s gauge\n", name, name)
+	for _, src := range sources {
+		if t := m.sources[src].lastReceived; !t.IsZero() {
+			fmt.Fprintf(&sb, "%s{src=%s} %s\n", name, quote(src), formatFloat(float64(t.UnixNano())/1e9))
+		}
+	}
+
+	name = "osc_handler_duration_seconds"
+	fmt.Fprintf(&sb, "# HELP %s Time spent in handlers per address.\n# TYPE %s histogram\n", name, name)
+	for _, address := range sortedKeys(m.handlers) {
+		h := m.handlers[address]
+		for i, le := range m.buckets {
+			fmt.Fprintf(&sb, "%s_bucket{address=%s,le=%q} %d\n", name, quote(address), formatFloat(le), h.buckets[i])
+		}
+		fmt.Fprintf(&sb, "%s_bucket{address=%s,le=\"+Inf\"} %d\n", name, quote(address), h.count)
+		fmt.Fprintf(&sb, "%s_sum{address=%s} %s\n", name, quote(address), formatFloat(h.sum))
+		fmt.Fprintf(&sb, "%s_count{address=%s} %d\n", name, quote(address), h.count)
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// quote returns s as a quoted label value.
+func quote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,114 @@
+package oscprom
+
+import (
+	"errors"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loffa/gosc"
+)
+
+func TestMetrics_ServeHTTP(t *testing.T) {
+	m := NewMetrics()
+	m.now = func() time.Time { return time.Unix(1700000000, 0) }
+	src := &net.UDPAddr{IP: net.IPv4(192, 168, 1, 10), Port: 9000}
+	m.PackageReceived(src, 16)
+	m.PackageReceived(&net.UDPAddr{IP: net.IPv4(192, 168, 1, 10), Port: 9001}, 8)
+	m.PackageDropped(src, errors.New("bad packet"))
+	m.MessageUnrouted("/unknown", src)
+	m.MessageHandled("/fader", src, 2*time.Millisecond)
+	m.PackageSent(src, nil)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	out := rec.Body.String()
+	for _, expected := range []string{
+		`osc_packages_received_total{src="192.168.1.10"} 2`,
+		`osc_received_bytes_total{src="192.168.1.10"} 24`,
+		`osc_packages_dropped_total{src="192.168.1.10"} 1`,
+		`osc_messages_unrouted_total{src="192.168.1.10"} 1`,
+		`osc_packages_sent_total{src="192.168.1.10"} 1`,
+		`osc_last_received_timestamp_seconds{src="192.168.1.10"} 1.7e+09`,
+		`osc_handler_duration_seconds_bucket{address="/fader",le="0.001"} 0`,
+		`osc_handler_duration_seconds_bucket{address="/fader",le="0.005"} 1`,
+		`osc_handler_duration_seconds_bucket{address="/fader",le="+Inf"} 1`,
+		`osc_handler_duration_seconds_count{address="/fader"} 1`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected output to contain %s but got:\n%s", expected, out)
+		}
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("expected text content type but got: %s", ct)
+	}
+}
+
+func TestMetrics_SetMaxSources(t *testing.T) {
+	m := NewMetrics()
+	m.SetMaxSources(2)
+	for i := 1; i <= 5; i++ {
+		m.PackageReceived(&net.UDPAddr{IP: net.IPv4(10, 0, 0, byte(i)), Port: 9000}, 8)
+	}
+	m.PackageReceived(&net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 9000}, 8)
+	if len(m.sources) != 3 {
+		t.Errorf("expected 2 sources and other but got: %d", len(m.sources))
+	}
+	out := strings.Builder{}
+	_ = m.WriteText(&out)
+	for _, expected := range []string{
+		`osc_packages_received_total{src="10.0.0.1"} 2`,
+		`osc_packages_received_total{src="10.0.0.2"} 1`,
+		`osc_packages_received_total{src="other"} 3`,
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected output to contain %s but got:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestMetrics_server(t *testing.T) {
+	m := NewMetrics()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	address := conn.LocalAddr().String()
+	_ = conn.Close()
+	trans, err := gosc.NewUDPListen(address, 0)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	srv := gosc.NewServer(&gosc.ServerOptions{Metrics: m})
+	mux := gosc.NewMux(nil)
+	mux.HandleMessageFunc("/hello", func(w *gosc.ResponseWriter, msg *gosc.Message) {
+		_ = w.Send(&gosc.Message{Address: msg.Address})
+	})
+	go func() {
+		_ = srv.Serve(trans, mux)
+	}()
+	defer srv.Shutdown()
+
+	cli, err := gosc.NewClient(address)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer cli.Close()
+	if _, err := cli.CallMessage("/hello"); err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+
+	sb := strings.Builder{}
+	_ = m.WriteText(&sb)
+	for _, expected := range []string{
+		`osc_packages_received_total{src="127.0.0.1"} 1`,
+		`osc_packages_sent_total{src="127.0.0.1"} 1`,
+		`osc_handler_duration_seconds_count{address="/hello"} 1`,
+	} {
+		if !strings.Contains(sb.String(), expected) {
+			t.Errorf("expected output to contain %s but got:\n%s", expected, sb.String())
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package gosc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+)
+
+// MaxDatagramSize is the largest payload of a UDP datagram over IPv4, and the
+// default buffer size of datagram transports.
+const MaxDatagramSize = 65507
+
+// TruncatedError is returned by datagram transports when a datagram is larger
+// than the buffer size and could not be read completely.
+type TruncatedError struct {
+	// From is the sender of the datagram.
+	From net.Addr
+	// BufferSize is the configured buffer size of the transport.
+	BufferSize int
+}
+
+func (e *TruncatedError) Error() string {
+	return fmt.Sprintf("datagram from %v exceeds buffer size %d", e.From, e.BufferSize)
+}
+
+// transportPacket is a Transport for datagram sockets, e.g. UDP and unixgram.
+type transportPacket struct {
+	transportMetrics
+	conn net.PacketConn
+	// bufferSize is the largest datagram received, defaults to
+	// MaxDatagramSize.
+	bufferSize int
+	// removeOnClose is a path removed when the transport is closed, used for
+	// the socket files of unixgram transports.
+	removeOnClose string
+	// receiveMu guards buf, which is reused by every Receive since decoding
+	// copies the data out of it.
+	receiveMu sync.Mutex
+	buf       []byte
+}
+
+// Send uses buffering to send a complete Package on the socket. The addr can
+// be nil for connected sockets.
+func (t *transportPacket) Send(pack Package, addr net.Addr) error {
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+
+	err := writePackage(pack, w)
+	if err != nil {
+		return err
+	}
+	_ = w.Flush()
+	if conn, ok := t.conn.(net.Conn); ok && addr == nil {
+		_, err = conn.Write(buf.Bytes())
+		return err
+	}
+	_, err = t.conn.WriteTo(buf.Bytes(), addr)
+	if errors.Is(err, net.ErrWriteToConnected) {
+		_, err = t.conn.(net.Conn).Write(buf.Bytes())
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Receive reads a datagram from the socket and returns the Package in it. A
+// TruncatedError is returned if the datagram is larger than the buffer size,
+// and a DecodeError if it is not a valid packet.
+func (t *transportPacket) Receive() (pack Package, from net.Addr, err error) {
+	size := t.bufferSize
+	if size <= 0 {
+		size = MaxDatagramSize
+	}
+	t.receiveMu.Lock()
+	defer t.receiveMu.Unlock()
+	// One extra byte is read to detect datagrams larger than the buffer, the
+	// rest of the datagram is discarded by the socket.
+	if len(t.buf) != size+1 {
+		t.buf = make([]byte, size+1)
+	}
+	buf := t.buf
+	n, from, err := t.conn.ReadFrom(buf)
+	if err != nil {
+		return nil, from, err
+	}
+	t.packageReceived(from, n)
+	if n > size {
+		return nil, from, &TruncatedError{From: from, BufferSize: size}
+	}
+	pack, err = decodePackage(buf[:n])
+	if err != nil {
+		return nil, from, &DecodeError{From: from, Err: err}
+	}
+	return pack, from, nil
+}
+
+// setSocketBuffers sets the receive and send buffer sizes of the socket, if
+// not zero.
+func setSocketBuffers(trans Transport, readBuffer, writeBuffer int) error {
+	conn, ok := trans.(*transportPacket)
+	if !ok {
+		return nil
+	}
+	sock, ok := conn.conn.(interface {
+		SetReadBuffer(bytes int) error
+		SetWriteBuffer(bytes int) error
+	})
+	if !ok {
+		return nil
+	}
+	if readBuffer > 0 {
+		if err := sock.SetReadBuffer(readBuffer); err != nil {
+			return err
+		}
+	}
+	if writeBuffer > 0 {
+		return sock.SetWriteBuffer(writeBuffer)
+	}
+	return nil
+}
+
+// Close closes the socket and removes the socket file, if any.
+func (t *transportPacket) Close() error {
+	err := t.conn.Close()
+	if t.removeOnClose != "" {
+		_ = os.RemoveAll(t.removeOnClose)
+	}
+	return err
+}
@@ -0,0 +1,152 @@
+package gosc
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is reported to Metrics for packages dropped by a
+// RateLimiter.
+var ErrRateLimited = errors.New("rate limited")
+
+// RateLimitOptions is the configuration of a RateLimiter.
+type RateLimitOptions struct {
+	// Rate is the number of packages per second allowed for each key, it
+	// must be positive.
+	Rate float64
+	// Burst is the number of packages allowed at once for each key, defaults
+	// to Rate rounded up.
+	Burst int
+	// Key returns the key of the bucket counting the package, defaults to
+	// SourceKey. Use SourceAddressKey to limit each address of a source
+	// separately.
+	Key func(src net.Addr, pkg Package) string
+	// Policy is applied to packages exceeding the limit, defaults to
+	// DropSilently.
+	Policy DropPolicy
+	// OnLimit is called for every package exceeding the limit, if not nil.
+	OnLimit func(src net.Addr, pkg Package)
+}
+
+// SourceKey returns the host of src, without the port, so that all packages of
+// a sender share the same limit.
+func SourceKey(src net.Addr, _ Package) string {
+	if src == nil {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(src.String()); err == nil {
+		return host
+	}
+	return src.String()
+}
+
+// SourceAddressKey returns the host of src together with the address of the
+// message, so that every address of a sender has its own limit. Bundles
+// share the limit of "#bundle".
+func SourceAddressKey(src net.Addr, pkg Package) string {
+	return SourceKey(src, pkg) + " " + packageAddress(pkg)
+}
+
+// bucketSweepInterval is how often buckets that are full again are removed.
+const bucketSweepInterval = time.Minute
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter is a PackageHandler limiting the rate of packages passed to the
+// next handler using a token bucket for each key, by default the source of
+// the package. Created by NewRateLimiter.
+type RateLimiter struct {
+	next      PackageHandler
+	opts      RateLimitOptions
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewRateLimiter returns a RateLimiter passing packages within the limits of
+// opts to next. It returns error if the rate is not positive.
+func NewRateLimiter(next PackageHandler, opts RateLimitOptions) (*RateLimiter, error) {
+	if !(opts.Rate > 0) || math.IsInf(opts.Rate, 1) {
+		return nil, fmt.Errorf("invalid rate %v, must be positive", opts.Rate)
+	}
+	if opts.Burst <= 0 {
+		opts.Burst = int(opts.Rate)
+		if float64(opts.Burst) < opts.Rate || opts.Burst == 0 {
+			opts.Burst++
+		}
+	}
+	if opts.Key == nil {
+		opts.Key = SourceKey
+	}
+	return &RateLimiter{
+		next:    next,
+		opts:    opts,
+		buckets: map[string]*tokenBucket{},
+		now:     time.Now,
+	}, nil
+}
+
+// HandlePackage passes pkg to the next handler if the limit of its key is not
+// exceeded, otherwise the package is dropped according to the policy.
+func (l *RateLimiter) HandlePackage(w *ResponseWriter, pkg Package) {
+	var src net.Addr
+	if w != nil {
+		src = w.src
+	}
+	if l.allow(l.opts.Key(src, pkg)) {
+		l.next.HandlePackage(w, pkg)
+		return
+	}
+
+	if l.opts.OnLimit != nil {
+		l.opts.OnLimit(src, pkg)
+	}
+	drop(w, pkg, ErrRateLimited, slog.LevelDebug, l.opts.Policy)
+}
+
+// allow takes a token from the bucket of key, if available.
+func (l *RateLimiter) allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(l.opts.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.opts.Rate
+	if b.tokens > float64(l.opts.Burst) {
+		b.tokens = float64(l.opts.Burst)
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// sweep removes the buckets that are full again, which behave like new ones,
+// so that the number of buckets does not grow with every source seen.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.opts.Rate >= float64(l.opts.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+}
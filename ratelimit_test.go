@@ -0,0 +1,114 @@
+package gosc
+
+import (
+	"math"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	next := HandlerFunc(func(_ *ResponseWriter, _ Package) {})
+	for _, rate := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		if _, err := NewRateLimiter(next, RateLimitOptions{Rate: rate}); err == nil {
+			t.Errorf("expected error for rate %v but none given", rate)
+		}
+	}
+	l, err := NewRateLimiter(next, RateLimitOptions{Rate: 0.5})
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if l.opts.Burst != 1 {
+		t.Errorf("expected burst 1 but got: %d", l.opts.Burst)
+	}
+}
+
+func TestRateLimiter_HandlePackage(t *testing.T) {
+	src := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 9000}
+	other := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 9000}
+	newLimiter := func(opts RateLimitOptions) (*RateLimiter, *int, *time.Time) {
+		handled := 0
+		l, err := NewRateLimiter(HandlerFunc(func(_ *ResponseWriter, _ Package) { handled++ }), opts)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		now := time.Unix(0, 0)
+		l.now = func() time.Time { return now }
+		return l, &handled, &now
+	}
+
+	t.Run("burst", func(t *testing.T) {
+		l, handled, now := newLimiter(RateLimitOptions{Rate: 10, Burst: 2})
+		w := NewResponseWriter(&testTransport{}, src)
+		for i := 0; i < 3; i++ {
+			l.HandlePackage(w, &Message{Address: "/fader"})
+		}
+		if *handled != 2 {
+			t.Errorf("expected 2 packages handled but got: %d", *handled)
+		}
+		*now = now.Add(100 * time.Millisecond)
+		l.HandlePackage(w, &Message{Address: "/fader"})
+		if *handled != 3 {
+			t.Errorf("expected package handled after refill but got: %d", *handled)
+		}
+	})
+	t.Run("perSource", func(t *testing.T) {
+		l, handled, _ := newLimiter(RateLimitOptions{Rate: 1})
+		l.HandlePackage(NewResponseWriter(&testTransport{}, src), &Message{Address: "/fader"})
+		l.HandlePackage(NewResponseWriter(&testTransport{}, src), &Message{Address: "/fader"})
+		l.HandlePackage(NewResponseWriter(&testTransport{}, other), &Message{Address: "/fader"})
+		if *handled != 2 {
+			t.Errorf("expected 2 packages handled but got: %d", *handled)
+		}
+	})
+	t.Run("sourceAddressKey", func(t *testing.T) {
+		l, handled, _ := newLimiter(RateLimitOptions{Rate: 1, Key: SourceAddressKey})
+		w := NewResponseWriter(&testTransport{}, src)
+		l.HandlePackage(w, &Message{Address: "/fader/1"})
+		l.HandlePackage(w, &Message{Address: "/fader/2"})
+		l.HandlePackage(w, &Message{Address: "/fader/1"})
+		if *handled != 2 {
+			t.Errorf("expected 2 packages handled but got: %d", *handled)
+		}
+	})
+	t.Run("dropAndReply", func(t *testing.T) {
+		var limited []net.Addr
+		l, _, _ := newLimiter(RateLimitOptions{
+			Rate:    1,
+			Policy:  DropAndReply,
+			OnLimit: func(src net.Addr, _ Package) { limited = append(limited, src) },
+		})
+		trans := &testTransport{}
+		w := NewResponseWriter(trans, src)
+		l.HandlePackage(w, &Message{Address: "/fader"})
+		l.HandlePackage(w, &Message{Address: "/fader"})
+		if len(limited) != 1 || limited[0] != src {
+			t.Errorf("expected OnLimit to be called with source but got: %v", limited)
+		}
+		if len(trans.sent) != 1 {
+			t.Fatalf("expected 1 reply but got: %d", len(trans.sent))
+		}
+		msg := trans.sent[0].(*Message)
+		if msg.Address != ErrorAddress || msg.Arguments[0] != "/fader" || msg.Arguments[1] != "rate limited" {
+			t.Errorf("expected rate limited reply but got: %v", msg)
+		}
+	})
+	t.Run("sweep", func(t *testing.T) {
+		l, _, now := newLimiter(RateLimitOptions{Rate: 1})
+		l.HandlePackage(NewResponseWriter(&testTransport{}, src), &Message{Address: "/fader"})
+		*now = now.Add(bucketSweepInterval)
+		l.HandlePackage(NewResponseWriter(&testTransport{}, other), &Message{Address: "/fader"})
+		if _, ok := l.buckets["10.0.0.1"]; ok || len(l.buckets) != 1 {
+			t.Errorf("expected full bucket to be removed but got: %v", l.buckets)
+		}
+	})
+}
+
+func TestSourceKey(t *testing.T) {
+	if key := SourceKey(&net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 9000}, nil); key != "10.0.0.1" {
+		t.Errorf("expected host without port but got: %q", key)
+	}
+	if key := SourceKey(nil, nil); key != "" {
+		t.Errorf("expected empty key but got: %q", key)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"io"
 )
 
+// readPackage reads a Package from r, which must buffer the whole packet so
+// that lengths read from it can be checked against the packet.
 func readPackage(r *bufio.Reader) (pack Package, err error) {
 	firstByte, err := r.Peek(1)
 	if err != nil {
@@ -18,6 +20,8 @@ func readPackage(r *bufio.Reader) (pack Package, err error) {
 		pack, err = readMessage(r)
 	} else if firstByte[0] == '#' {
 		pack, err = readBundle(r)
+	} else {
+		err = fmt.Errorf("unknown package start '%c'", firstByte[0])
 	}
 
 	return
@@ -40,19 +44,14 @@ func readBundle(r *bufio.Reader) (bundle *Bundle, err error) {
 	}
 
 	for {
-		length := int32(0)
-		if err := binary.Read(r, binary.BigEndian, &length); err == io.EOF {
+		if _, err := r.Peek(1); err == io.EOF {
 			return bundle, nil
-		} else if err != nil {
-			return nil, err
 		}
-		buf := make([]byte, length)
-		bufR := bufio.NewReader(bytes.NewReader(buf))
-		_, err = io.ReadFull(r, buf)
+		buf, err := readSized(r)
 		if err != nil {
 			return nil, err
 		}
-		pack, err := readPackage(bufR)
+		pack, err := readPackage(bufio.NewReaderSize(bytes.NewReader(buf), len(buf)+1))
 		if err != nil {
 			return nil, err
 		}
@@ -81,6 +80,27 @@ func readMessage(r *bufio.Reader) (msg *Message, err error) {
 	}, nil
 }
 
+// readSized reads an int32 size followed by that many bytes. Sizes that are
+// negative or larger than the rest of the packet are rejected before
+// allocating.
+func readSized(r *bufio.Reader) ([]byte, error) {
+	n := int32(0)
+	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("negative size %d", n)
+	}
+	data, err := r.Peek(int(n))
+	if err != nil {
+		return nil, fmt.Errorf("size %d exceeds packet", n)
+	}
+	res := make([]byte, n)
+	copy(res, data)
+	_, err = r.Discard(int(n))
+	return res, err
+}
+
 func readPaddedString(r *bufio.Reader) (str string, err error) {
 	str, err = r.ReadString(0)
 	if err != nil {
@@ -100,13 +120,28 @@ func readArguments(r *bufio.Reader) ([]any, error) {
 	if err != nil {
 		return nil, err
 	}
-	if typeTags[0] != ',' {
+	if len(typeTags) == 0 || typeTags[0] != ',' {
 		return nil, errors.New("typetag format error")
 	}
 	typeTags = typeTags[1:]
 	res := make([]any, 0, len(typeTags))
+	// parents holds the enclosing argument lists while reading an array.
+	var parents [][]any
 
 	for _, tt := range typeTags {
+		switch typeTag(tt) {
+		case TypeTagArrayStart:
+			parents = append(parents, res)
+			res = make([]any, 0)
+			continue
+		case TypeTagArrayStop:
+			if len(parents) == 0 {
+				return nil, errors.New("typetag array end without start")
+			}
+			res = append(parents[len(parents)-1], res)
+			parents = parents[:len(parents)-1]
+			continue
+		}
 		decoder, ok := readerMap[typeTag(tt)]
 		if !ok {
 			return nil, fmt.Errorf("unknown tag type '%s'", string(tt))
@@ -117,5 +152,8 @@ func readArguments(r *bufio.Reader) ([]any, error) {
 		}
 		res = append(res, val)
 	}
+	if len(parents) != 0 {
+		return nil, errors.New("typetag array start without end")
+	}
 	return res, nil
 }
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"errors"
+	"image/color"
 	"io"
 	"reflect"
 	"testing"
@@ -152,3 +153,91 @@ func Test_readPaddedString(t *testing.T) {
 		buf.Reset()
 	})
 }
+
+func Test_readArguments_roundTrip(t *testing.T) {
+	buf := bytes.Buffer{}
+	r := bufio.NewReader(&buf)
+	w := bufio.NewWriter(&buf)
+
+	t.Run("nestedArray", func(t *testing.T) {
+		args := []any{int32(1), []any{"a", []any{float32(2)}}, int32(3)}
+		_ = writeArguments(w, args)
+		_ = w.Flush()
+		res, err := readArguments(r)
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+		if !reflect.DeepEqual(res, args) {
+			t.Errorf("expected %v but got: %v", args, res)
+		}
+		buf.Reset()
+	})
+	t.Run("allTypes", func(t *testing.T) {
+		args := []any{
+			true, []byte{1}, false, int64(-2), float64(0.5), Symbol("sym"), Char('c'),
+			color.RGBA{R: 1, G: 2, B: 3, A: 4}, MIDI{Status: 0x90, Data1: 60, Data2: 127}, nil, Infinitum{},
+		}
+		_ = writeArguments(w, args)
+		_ = w.Flush()
+		res, err := readArguments(r)
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+		if !reflect.DeepEqual(res, args) {
+			t.Errorf("expected %v but got: %v", args, res)
+		}
+		buf.Reset()
+	})
+	t.Run("unbalancedArray", func(t *testing.T) {
+		_ = writePaddedString(w, ",[i")
+		_, _ = w.Write([]byte{0, 0, 0, 1})
+		_ = w.Flush()
+		_, err := readArguments(r)
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+		buf.Reset()
+	})
+}
+
+func Test_readPackage_malformed(t *testing.T) {
+	bundleHeader := []byte("#bundle\x00\x00\x00\x00\x00\x00\x00\x00\x01")
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"negativeBlobSize", []byte("/a\x00\x00,b\x00\x00\xff\xff\xff\xff")},
+		{"largeBlobSize", []byte("/a\x00\x00,b\x00\x00\x7f\xff\xff\xff")},
+		{"negativeElementSize", append(bundleHeader, 0xff, 0xff, 0xff, 0xf0)},
+		{"largeElementSize", append(bundleHeader, 0x7f, 0xff, 0xff, 0xff)},
+		{"emptyTypeTags", []byte("/a\x00\x00\x00\x00\x00\x00")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readPackage(bufio.NewReaderSize(bytes.NewReader(tt.data), len(tt.data)+1))
+			if err == nil {
+				t.Error("expected error but none given")
+			}
+		})
+	}
+}
+
+func Test_decodePackage(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		pkg, err := decodePackage([]byte("/a\x00\x00,i\x00\x00\x00\x00\x00\x01"))
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if i, _ := pkg.(*Message).Int32(0); i != 1 {
+			t.Errorf("expected argument 1 but got: %v", pkg)
+		}
+	})
+	t.Run("panic", func(t *testing.T) {
+		readerMap['P'] = func(_ *bufio.Reader) (any, error) { panic("decoder bug") }
+		defer delete(readerMap, 'P')
+		_, err := decodePackage([]byte("/a\x00\x00,P\x00\x00"))
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package gosc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log"
+	"log/slog"
+	"net"
+	"runtime/debug"
+)
+
+// PanicError is passed to the error handlers of servers and clients when a
+// handler or receiver panics. The panic is recovered and the next package is
+// handled as usual.
+type PanicError struct {
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the go-routine that panicked.
+	Stack []byte
+	// Src is the sender of the package, if known.
+	Src net.Addr
+	// Package is the package that was handled.
+	Package Package
+}
+
+func newPanicError(value any, src net.Addr, pkg Package) *PanicError {
+	return &PanicError{
+		Value:   value,
+		Stack:   debug.Stack(),
+		Src:     src,
+		Package: pkg,
+	}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic handling %v from %v: %v", e.Package, e.Src, e.Value)
+}
+
+// logPanic is used to report panics when neither an error handler nor a
+// logger is set.
+func logPanic(err *PanicError) {
+	log.Printf("%v\n%s", err, err.Stack)
+}
+
+// logPanicTo logs a recovered panic to logger.
+func logPanicTo(logger *slog.Logger, err *PanicError) {
+	attrs := append([]any{"src", err.Src}, packageAttrs(logger, err.Package)...)
+	attrs = append(attrs, "panic", err.Value, "stack", string(err.Stack))
+	logger.Error("recovered panic", attrs...)
+}
+
+// decodePackage decodes the packet in data. Panics of the decoder are
+// recovered and returned as an error, so that a malformed packet from an
+// untrusted sender can not crash the process.
+func decodePackage(data []byte) (pack Package, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			pack, err = nil, fmt.Errorf("panic decoding packet: %v", v)
+		}
+	}()
+	return readPackage(bufio.NewReaderSize(bytes.NewReader(data), len(data)+1))
+}
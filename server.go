@@ -1,6 +1,15 @@
 package gosc
 
-import "net"
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"sync"
+)
 
 // PackageHandler provides an interface dealing with any OSC package
 type PackageHandler interface {
@@ -41,62 +50,234 @@ func (h HandlerFunc) HandlePackage(writer *ResponseWriter, pkg Package) {
 // created using the NewServer method.
 type Server struct {
 	opts           *ServerOptions
+	logger         *slog.Logger
+	metrics        Metrics
+	mu             sync.Mutex
 	transport      Transport
 	packageHandler PackageHandler
-	exiting        chan bool
+	closing        bool
+	done           chan struct{}
 }
 
 // ServerOptions is the configuration parameters used to create a Server.
 type ServerOptions struct {
-	// Size for read buffer, defaults to 512
+	// BufferSize is the largest datagram received, larger datagrams are
+	// dropped with a TruncatedError. Defaults to MaxDatagramSize.
 	BufferSize int
+	// ReadBuffer and WriteBuffer are the receive and send buffer sizes of
+	// datagram sockets, the system defaults are used if zero.
+	ReadBuffer  int
+	WriteBuffer int
+	// MaxPacketSize is the largest packet accepted on stream transports,
+	// defaults to DefaultMaxPacketSize.
+	MaxPacketSize int
+	// ConnState is called when a stream transport accepts or closes a
+	// connection.
+	ConnState func(addr net.Addr, state ConnState)
+	// Multicast is used when listening on a multicast group address, nil
+	// uses the defaults.
+	Multicast *MulticastOptions
+	// ErrorHandler is called with the errors of receiving packages and the
+	// address of the sender, if known. Packages that can not be decoded are
+	// skipped and an AcceptError of stream servers is retried, other errors
+	// stop the server. Errors are ignored if nil.
+	ErrorHandler func(err error, src net.Addr)
+	// Panics of the handler are recovered and passed to the ErrorHandler as
+	// a *PanicError, or logged with the stack trace if it is nil.
+	// PanicReply makes the server reply to the sender with a Message on
+	// ErrorAddress with the address of the package and "internal error".
+	PanicReply bool
+	// Broadcast makes UDP servers listen on all addresses of the host, for
+	// the port of the address, so that broadcasts to the port are received.
+	// Responses are sent unicast to the sender.
+	Broadcast bool
+	// Logger is used to log the server starting and stopping, packages that
+	// are dropped or not routed, failed responses and recovered panics.
+	// Received packages are logged at the debug level. Nothing is logged if
+	// nil.
+	Logger *slog.Logger
+	// Metrics receives instrumentation events of the server, its transport
+	// and Mux, if not nil.
+	Metrics Metrics
+	// TLSConfig is required for "tls://" and "tls+slip://" addresses. Set
+	// ClientAuth to tls.RequireAndVerifyClientCert for mutual TLS, the client
+	// certificates are available with ResponseWriter.TLS.
+	TLSConfig *tls.Config
 }
 
 // NewServer initializes and returns a Server with options applied. Options not
 // set get their default values.
 func NewServer(opts *ServerOptions) *Server {
 	if opts.BufferSize == 0 {
-		opts.BufferSize = 512
+		opts.BufferSize = MaxDatagramSize
 	}
 
 	return &Server{
 		opts:    opts,
-		exiting: make(chan bool),
+		logger:  loggerOrDiscard(opts.Logger),
+		metrics: metricsOrNop(opts.Metrics),
 	}
 }
 
 // ListenAndServe listens on the UDP address specified and then calls
-// the PackageHandler for incoming packages.
+// the PackageHandler for incoming packages. TCP is used instead if the address
+// is prefixed with "tcp://", or "tcp+slip://" for SLIP framing, TLS if
+// prefixed with "tls://" or "tls+slip://", and a unix datagram socket if
+// prefixed with "unixgram://". The multicast group is
+// joined if the UDP address is a multicast address.
 //
-// ListenAndServe returns error if the address is malformed or can't be opened.
+// ListenAndServe returns error if the address is malformed or can't be opened,
+// or when receiving fails. It returns nil after Shutdown.
 func (s *Server) ListenAndServe(addr string, handler PackageHandler) error {
-	trans, err := NewUDPListen(addr, s.opts.BufferSize)
+	trans, err := s.listenTransport(addr)
 	if err != nil {
 		return err
 	}
+	defer trans.(io.Closer).Close()
+	if err := setSocketBuffers(trans, s.opts.ReadBuffer, s.opts.WriteBuffer); err != nil {
+		return err
+	}
+	s.logger.Info("server listening", "address", addr)
+	return s.Serve(trans, handler)
+}
+
+// Serve calls the PackageHandler for packages received on the Transport until
+// receiving fails, see ListenAndServe.
+func (s *Server) Serve(trans Transport, handler PackageHandler) error {
+	s.mu.Lock()
+	if s.closing {
+		s.mu.Unlock()
+		return nil
+	}
 	s.transport = trans
 	s.packageHandler = handler
-	s.listen()
-	return nil
+	s.done = make(chan struct{})
+	s.mu.Unlock()
+	if s.opts.Metrics != nil {
+		setTransportMetrics(trans, s.opts.Metrics)
+	}
+
+	defer close(s.done)
+	err := s.listen()
+	if err != nil {
+		s.logger.Error("server stopped", "error", err)
+	} else {
+		s.logger.Info("server stopped")
+	}
+	return err
 }
 
-// Shutdown waits for all ongoing requests and then shuts down the server and
-// releases the port.
-func (s *Server) Shutdown() error {
-	// TODO: Handle waiting for shutdown to complete
-	s.exiting <- true
-	return nil
+func (s *Server) listenTransport(address string) (Transport, error) {
+	network, addr := splitAddress(address)
+	switch network {
+	case "udp":
+		if isMulticastAddress(addr) {
+			return NewMulticastListen(addr, s.opts.BufferSize, s.opts.Multicast)
+		}
+		if s.opts.Broadcast {
+			addr = broadcastListenAddress(addr)
+		}
+		return NewUDPListen(addr, s.opts.BufferSize)
+	case "tcp", "tcp+slip":
+		return NewTCPListen(addr, &TCPOptions{
+			Framing:       streamFraming(network),
+			MaxPacketSize: s.opts.MaxPacketSize,
+			ConnState:     s.opts.ConnState,
+		})
+	case "tls", "tls+slip":
+		if s.opts.TLSConfig == nil {
+			return nil, errors.New("tls requires ServerOptions.TLSConfig")
+		}
+		return NewTCPListen(addr, &TCPOptions{
+			Framing:       streamFraming(network),
+			MaxPacketSize: s.opts.MaxPacketSize,
+			ConnState:     s.opts.ConnState,
+			TLSConfig:     s.opts.TLSConfig,
+		})
+	case "unixgram":
+		return NewUnixgramListen(addr, s.opts.BufferSize)
+	}
+	return nil, fmt.Errorf("unsupported network %q", network)
 }
 
-func (s *Server) listen() {
-	var pkg Package
+// Shutdown closes the transport, which releases the port, and then waits for
+// the ongoing request to complete. It must not be called from a handler.
+func (s *Server) Shutdown() error {
+	s.mu.Lock()
+	s.closing = true
+	trans, done := s.transport, s.done
+	s.mu.Unlock()
+	if trans == nil {
+		return nil
+	}
+	s.logger.Info("server shutting down")
+
 	var err error
-	var src net.Addr
-	for pkg, src, err = s.transport.Receive(); err == nil; pkg, src, err = s.transport.Receive() {
-		rw := &ResponseWriter{
-			src:   src,
-			trans: s.transport,
+	if closer, ok := trans.(io.Closer); ok {
+		err = closer.Close()
+	}
+	<-done
+	return err
+}
+
+func (s *Server) listen() error {
+	for {
+		pkg, src, err := s.transport.Receive()
+		if err == nil {
+			s.handle(pkg, src)
+			continue
+		}
+		if s.isClosing() {
+			return nil
+		}
+		if s.opts.ErrorHandler != nil {
+			s.opts.ErrorHandler(err, src)
+		}
+		if !isTransientError(err) {
+			return err
 		}
-		s.packageHandler.HandlePackage(rw, pkg)
+		var acceptErr *AcceptError
+		if errors.As(err, &acceptErr) {
+			s.logger.Error("accepting connection failed", "error", err)
+			continue
+		}
+		s.logger.Warn("dropped package", "src", src, "error", err)
+		s.metrics.PackageDropped(src, err)
 	}
 }
+
+// handle calls the handler, recovering from panics.
+func (s *Server) handle(pkg Package, src net.Addr) {
+	w := NewResponseWriter(s.transport, src)
+	w.logger = s.logger
+	w.metrics = s.metrics
+	defer func() {
+		if v := recover(); v != nil {
+			err := newPanicError(v, src, pkg)
+			if s.logger != discardLogger {
+				logPanicTo(s.logger, err)
+			}
+			if s.opts.ErrorHandler != nil {
+				s.opts.ErrorHandler(err, src)
+			} else if s.logger == discardLogger {
+				logPanic(err)
+			}
+			if s.opts.PanicReply {
+				_ = w.Send(&Message{
+					Address:   ErrorAddress,
+					Arguments: []any{packageAddress(pkg), "internal error"},
+				})
+			}
+		}
+	}()
+	if s.logger.Enabled(context.Background(), slog.LevelDebug) {
+		s.logger.Debug("received package", append([]any{"src", src}, packageAttrs(s.logger, pkg)...)...)
+	}
+	s.packageHandler.HandlePackage(w, pkg)
+}
+
+func (s *Server) isClosing() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closing
+}
@@ -1,7 +1,10 @@
 package gosc
 
 import (
+	"errors"
+	"net"
 	"testing"
+	"time"
 )
 
 func TestHandlerFunc_HandlePackage(t *testing.T) {
@@ -30,10 +33,133 @@ func TestServer_ListenAndServe(t *testing.T) {
 	// TODO: Implement me
 }
 
+func TestServer_Serve(t *testing.T) {
+	trans, err := NewTCPListen("127.0.0.1:0", nil)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer trans.(*transportTCPListen).Close()
+	mux := NewMux(nil)
+	mux.HandleMessageFunc("/hello", func(w *ResponseWriter, msg *Message) {
+		_ = w.Send(&Message{Address: msg.Address, Arguments: []any{"World"}})
+	})
+	go func() {
+		_ = NewServer(&ServerOptions{}).Serve(trans, mux)
+	}()
+
+	cli, err := NewClient("tcp://" + trans.(*transportTCPListen).ln.Addr().String())
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer cli.Close()
+	res, err := cli.CallMessage("/hello")
+	if err != nil {
+		t.Errorf("expected no error but got: %v", err)
+	}
+	if s, _ := res.StringArg(0); s != "World" {
+		t.Errorf("expected response \"World\" but got: %v", res)
+	}
+}
+
+func TestServer_handle(t *testing.T) {
+	trans := &testTransport{}
+	var handled error
+	srv := NewServer(&ServerOptions{
+		ErrorHandler: func(err error, src net.Addr) { handled = err },
+		PanicReply:   true,
+	})
+	srv.transport = trans
+	srv.packageHandler = HandlerFunc(func(w *ResponseWriter, pkg Package) {
+		_ = pkg.(*Message).Arguments[0].(string)
+	})
+	srv.handle(&Message{Address: "/crash"}, nil)
+
+	var panicErr *PanicError
+	if !errors.As(handled, &panicErr) || len(panicErr.Stack) == 0 {
+		t.Fatalf("expected PanicError with stack but got: %v", handled)
+	}
+	if panicErr.Package.(*Message).Address != "/crash" {
+		t.Errorf("expected offending package in error but got: %v", panicErr.Package)
+	}
+	if len(trans.sent) != 1 || trans.sent[0].(*Message).Address != ErrorAddress {
+		t.Errorf("expected reply on %s but got: %v", ErrorAddress, trans.sent)
+	}
+}
+
 func TestServer_Shutdown(t *testing.T) {
-	// TODO: Implement me
+	t.Run("notStarted", func(t *testing.T) {
+		if err := NewServer(&ServerOptions{}).Shutdown(); err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+	t.Run("running", func(t *testing.T) {
+		srv := NewServer(&ServerOptions{})
+		errs := make(chan error)
+		go func() {
+			errs <- srv.ListenAndServe("127.0.0.1:0", NewMux(nil))
+		}()
+		for {
+			srv.mu.Lock()
+			started := srv.transport != nil
+			srv.mu.Unlock()
+			if started {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if err := srv.Shutdown(); err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+		if err := <-errs; err != nil {
+			t.Errorf("expected ListenAndServe to return nil but got: %v", err)
+		}
+	})
 }
 
 func TestServer_listen(t *testing.T) {
-	// TODO: Implement me
+	t.Run("decodeError", func(t *testing.T) {
+		trans, _ := NewUDPListen("127.0.0.1:0", 0)
+		defer trans.(*transportPacket).Close()
+		type handledError struct {
+			err error
+			src net.Addr
+		}
+		errs := make(chan handledError, 1)
+		srv := NewServer(&ServerOptions{
+			ErrorHandler: func(err error, src net.Addr) { errs <- handledError{err, src} },
+		})
+		mux := NewMux(nil)
+		mux.HandleMessageFunc("/hello", func(w *ResponseWriter, msg *Message) {
+			_ = w.Send(&Message{Address: msg.Address})
+		})
+		go func() {
+			_ = srv.Serve(trans, mux)
+		}()
+
+		cli, err := NewUDPTransport(trans.(*transportPacket).conn.LocalAddr().String(), 0)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		defer cli.(*transportPacket).Close()
+		_, _ = cli.(*transportPacket).conn.(net.Conn).Write([]byte("garbage"))
+		_ = cli.Send(&Message{Address: "/hello"}, nil)
+
+		handled := <-errs
+		var decodeErr *DecodeError
+		if !errors.As(handled.err, &decodeErr) {
+			t.Errorf("expected DecodeError but got: %v", handled.err)
+		}
+		if handled.src.String() != cli.(*transportPacket).conn.LocalAddr().String() {
+			t.Errorf("expected error from client address but got: %v", handled.src)
+		}
+		if pkg, _, err := cli.Receive(); err != nil || pkg.(*Message).Address != "/hello" {
+			t.Errorf("expected response after decode error but got: %v, %v", pkg, err)
+		}
+	})
+	t.Run("fatalError", func(t *testing.T) {
+		err := NewServer(&ServerOptions{}).Serve(&testTransport{}, NewMux(nil))
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
 }
@@ -0,0 +1,209 @@
+package gosc
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+// SignedAddress is the address of the Message wrapping a signed package. Its
+// arguments are the key ID, the time of signing in Unix nanoseconds, a random
+// nonce, the encoded package and the HMAC-SHA256 of the Message with the
+// preceding arguments.
+const SignedAddress = "/gosc/signed"
+
+// DefaultSignatureMaxAge is the default of VerifyOptions.MaxAge.
+const DefaultSignatureMaxAge = 30 * time.Second
+
+// nonceSize is the number of random bytes of the nonce of a signed package.
+const nonceSize = 16
+
+// Errors of verifying signed packages, passed to VerifyOptions.OnReject and
+// reported to Metrics.
+var (
+	ErrUnsigned         = errors.New("package is not signed")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrExpired          = errors.New("signature expired")
+	ErrReplayed         = errors.New("signed package replayed")
+)
+
+// A Signer signs packages with HMAC-SHA256, to be verified by a Verifier with
+// the same key. Created by NewSigner.
+type Signer struct {
+	keyID string
+	key   []byte
+	now   func() time.Time
+}
+
+// NewSigner returns a Signer using key, identified by keyID so that keys can
+// be rotated.
+func NewSigner(keyID string, key []byte) *Signer {
+	return &Signer{keyID: keyID, key: key, now: time.Now}
+}
+
+// Sign returns a Message on SignedAddress wrapping pkg.
+func (s *Signer) Sign(pkg Package) (*Message, error) {
+	payload, err := encodePackage(pkg)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	msg := &Message{
+		Address:   SignedAddress,
+		Arguments: []any{s.keyID, s.now().UnixNano(), nonce, payload},
+	}
+	sig, err := signature(s.key, msg)
+	if err != nil {
+		return nil, err
+	}
+	msg.Arguments = append(msg.Arguments, sig)
+	return msg, nil
+}
+
+// overhead returns the largest number of bytes added to a package by Sign,
+// the size of the signed Message with an empty payload blob and the padding
+// of the payload blob, which is largest for payloads of 4n+1 bytes.
+func (s *Signer) overhead() int {
+	size, _ := encodedSize(&Message{
+		Address:   SignedAddress,
+		Arguments: []any{s.keyID, int64(0), make([]byte, nonceSize), []byte{}, make([]byte, sha256.Size)},
+	})
+	return size + getPadBytes(1)
+}
+
+// signature returns the HMAC-SHA256 of the encoded msg.
+func signature(key []byte, msg *Message) ([]byte, error) {
+	data, err := encodePackage(msg)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+// VerifyOptions is the configuration of a Verifier.
+type VerifyOptions struct {
+	// Keys are the keys accepted, by key ID.
+	Keys map[string][]byte
+	// MaxAge is the largest difference between the time of signing and
+	// receiving a package, defaults to DefaultSignatureMaxAge. The nonces of
+	// packages are remembered for MaxAge to reject replays.
+	MaxAge time.Duration
+	// Policy is applied to rejected packages, defaults to DropSilently.
+	// DropAndReply replies with the error.
+	Policy DropPolicy
+	// OnReject is called for every rejected package with the reason, if not
+	// nil.
+	OnReject func(src net.Addr, pkg Package, err error)
+}
+
+// Verifier is a PackageHandler passing the packages signed by a Signer, after
+// verifying the signature, to the next handler. Unsigned packages and replays
+// are rejected. Created by NewVerifier.
+type Verifier struct {
+	next      PackageHandler
+	opts      VerifyOptions
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewVerifier returns a Verifier passing the packages signed with the keys of
+// opts to next.
+func NewVerifier(next PackageHandler, opts VerifyOptions) *Verifier {
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = DefaultSignatureMaxAge
+	}
+	return &Verifier{
+		next:   next,
+		opts:   opts,
+		nonces: map[string]time.Time{},
+		now:    time.Now,
+	}
+}
+
+// HandlePackage verifies pkg and passes the package it wraps to the next
+// handler, otherwise pkg is rejected according to the policy.
+func (v *Verifier) HandlePackage(w *ResponseWriter, pkg Package) {
+	var src net.Addr
+	if w != nil {
+		src = w.src
+	}
+	inner, err := v.verify(pkg, src)
+	if err == nil {
+		v.next.HandlePackage(w, inner)
+		return
+	}
+
+	if v.opts.OnReject != nil {
+		v.opts.OnReject(src, pkg, err)
+	}
+	drop(w, pkg, err, slog.LevelWarn, v.opts.Policy)
+}
+
+// verify returns the package wrapped by pkg if it is correctly signed and not
+// replayed.
+func (v *Verifier) verify(pkg Package, src net.Addr) (Package, error) {
+	msg, ok := pkg.(*Message)
+	if !ok || msg.Address != SignedAddress || !msg.Matches("shbbb") {
+		return nil, ErrUnsigned
+	}
+	keyID, _ := msg.StringArg(0)
+	ts := msg.Arguments[1].(int64)
+	nonce, _ := msg.Blob(2)
+	payload, _ := msg.Blob(3)
+	sig, _ := msg.Blob(4)
+
+	key, ok := v.opts.Keys[keyID]
+	if !ok {
+		return nil, ErrInvalidSignature
+	}
+	expected, err := signature(key, &Message{Address: msg.Address, Arguments: msg.Arguments[:4]})
+	if err != nil || !hmac.Equal(sig, expected) {
+		return nil, ErrInvalidSignature
+	}
+	signed := time.Unix(0, ts)
+	if err := v.checkNonce(keyID+string(nonce), signed); err != nil {
+		return nil, err
+	}
+
+	inner, err := decodePackage(payload)
+	if err != nil {
+		return nil, &DecodeError{From: src, Err: err}
+	}
+	return inner, nil
+}
+
+// checkNonce returns ErrExpired if signed is not within MaxAge, or
+// ErrReplayed if nonce was already seen, and otherwise remembers the nonce.
+func (v *Verifier) checkNonce(nonce string, signed time.Time) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	now := v.now()
+	if age := now.Sub(signed); age > v.opts.MaxAge || age < -v.opts.MaxAge {
+		return ErrExpired
+	}
+	if now.Sub(v.lastSweep) >= v.opts.MaxAge {
+		v.lastSweep = now
+		for n, t := range v.nonces {
+			if now.Sub(t) > v.opts.MaxAge {
+				delete(v.nonces, n)
+			}
+		}
+	}
+	if _, ok := v.nonces[nonce]; ok {
+		return ErrReplayed
+	}
+	v.nonces[nonce] = signed
+	return nil
+}
@@ -0,0 +1,170 @@
+package gosc
+
+import (
+	"crypto/sha256"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSigner_Sign(t *testing.T) {
+	s := NewSigner("k1", []byte("secret"))
+	pkg := &Message{Address: "/scene/recall", Arguments: []any{int32(3)}}
+	msg, err := s.Sign(pkg)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if msg.Address != SignedAddress || !msg.Matches("shbbb") {
+		t.Errorf("expected signed message but got: %v", msg)
+	}
+	other, _ := s.Sign(&Message{Address: "/scene/recall", Arguments: []any{int32(3)}})
+	if nonce, _ := other.Blob(2); string(nonce) == string(msg.Arguments[2].([]byte)) {
+		t.Error("expected a new nonce for every package")
+	}
+	pkgSize, _ := encodedSize(pkg)
+	if size, _ := encodedSize(msg); size > pkgSize+s.overhead() {
+		t.Errorf("expected at most %d bytes but got: %d", pkgSize+s.overhead(), size)
+	}
+}
+
+func TestSigner_overhead(t *testing.T) {
+	s := NewSigner("k1", []byte("secret"))
+	for n := 0; n < 8; n++ {
+		msg := &Message{
+			Address:   SignedAddress,
+			Arguments: []any{s.keyID, int64(0), make([]byte, nonceSize), make([]byte, n), make([]byte, sha256.Size)},
+		}
+		if size, _ := encodedSize(msg); size > n+s.overhead() {
+			t.Errorf("expected at most %d bytes for a payload of %d bytes but got: %d", n+s.overhead(), n, size)
+		}
+	}
+}
+
+// testVerifier is a Verifier of the packages signed by signer, recording the
+// handled and rejected packages. Both use the fixed time now.
+type testVerifier struct {
+	*Verifier
+	signer   *Signer
+	now      time.Time
+	handled  []Package
+	rejected []error
+}
+
+func newTestVerifier(opts VerifyOptions) *testVerifier {
+	tv := &testVerifier{signer: NewSigner("k1", []byte("secret")), now: time.Unix(1700000000, 0)}
+	opts.Keys = map[string][]byte{"k1": []byte("secret")}
+	opts.OnReject = func(_ net.Addr, _ Package, err error) { tv.rejected = append(tv.rejected, err) }
+	tv.Verifier = NewVerifier(HandlerFunc(func(_ *ResponseWriter, pkg Package) {
+		tv.handled = append(tv.handled, pkg)
+	}), opts)
+	tv.Verifier.now = func() time.Time { return tv.now }
+	tv.signer.now = func() time.Time { return tv.now }
+	return tv
+}
+
+func (tv *testVerifier) sign(t *testing.T, pkg Package) *Message {
+	msg, err := tv.signer.Sign(pkg)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	return msg
+}
+
+func TestVerifier_HandlePackage(t *testing.T) {
+	src := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 9000}
+	t.Run("valid", func(t *testing.T) {
+		tv := newTestVerifier(VerifyOptions{})
+		tv.HandlePackage(NewResponseWriter(&testTransport{}, src), tv.sign(t, &Bundle{
+			Timetag:  Immediately,
+			Messages: []*Message{{Address: "/fader", Arguments: []any{float32(0.5)}}},
+		}))
+		if len(tv.handled) != 1 {
+			t.Fatalf("expected 1 package handled but got: %d", len(tv.handled))
+		}
+		if b, ok := tv.handled[0].(*Bundle); !ok || b.Messages[0].Address != "/fader" {
+			t.Errorf("expected unwrapped bundle but got: %v", tv.handled[0])
+		}
+	})
+	tests := []struct {
+		name   string
+		modify func(msg *Message) Package
+		want   error
+	}{
+		{"unsigned", func(msg *Message) Package { return &Message{Address: "/scene/recall"} }, ErrUnsigned},
+		{"malformed", func(msg *Message) Package { msg.Arguments = msg.Arguments[:1]; return msg }, ErrUnsigned},
+		{"tampered", func(msg *Message) Package {
+			payload := msg.Arguments[3].([]byte)
+			payload[len(payload)-1] = 4
+			return msg
+		}, ErrInvalidSignature},
+		{"unknownKey", func(msg *Message) Package { msg.Arguments[0] = "k2"; return msg }, ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tv := newTestVerifier(VerifyOptions{})
+			pkg := tt.modify(tv.sign(t, &Message{Address: "/scene/recall", Arguments: []any{int32(3)}}))
+			tv.HandlePackage(NewResponseWriter(&testTransport{}, src), pkg)
+			if len(tv.handled) != 0 {
+				t.Errorf("expected package not to be handled but got: %v", tv.handled)
+			}
+			if len(tv.rejected) != 1 || !errors.Is(tv.rejected[0], tt.want) {
+				t.Errorf("expected %v but got: %v", tt.want, tv.rejected)
+			}
+		})
+	}
+}
+
+func TestVerifier_checkNonce(t *testing.T) {
+	src := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 9000}
+	t.Run("replayed", func(t *testing.T) {
+		tv := newTestVerifier(VerifyOptions{Policy: DropAndReply})
+		trans := &testTransport{}
+		msg := tv.sign(t, &Message{Address: "/scene/recall"})
+		tv.HandlePackage(NewResponseWriter(trans, src), msg)
+		tv.HandlePackage(NewResponseWriter(trans, src), msg)
+		if len(tv.handled) != 1 || len(tv.rejected) != 1 || !errors.Is(tv.rejected[0], ErrReplayed) {
+			t.Errorf("expected replay to be rejected but got: %v, %v", tv.handled, tv.rejected)
+		}
+		if len(trans.sent) != 1 || trans.sent[0].(*Message).Arguments[1] != ErrReplayed.Error() {
+			t.Errorf("expected error reply but got: %v", trans.sent)
+		}
+	})
+	t.Run("expired", func(t *testing.T) {
+		tv := newTestVerifier(VerifyOptions{MaxAge: time.Second})
+		msg := tv.sign(t, &Message{Address: "/scene/recall"})
+		tv.now = tv.now.Add(2 * time.Second)
+		tv.HandlePackage(NewResponseWriter(&testTransport{}, src), msg)
+		if len(tv.rejected) != 1 || !errors.Is(tv.rejected[0], ErrExpired) {
+			t.Errorf("expected ErrExpired but got: %v", tv.rejected)
+		}
+	})
+	t.Run("sweep", func(t *testing.T) {
+		tv := newTestVerifier(VerifyOptions{MaxAge: time.Second})
+		tv.HandlePackage(NewResponseWriter(&testTransport{}, src), tv.sign(t, &Message{Address: "/test"}))
+		tv.now = tv.now.Add(2 * time.Second)
+		tv.HandlePackage(NewResponseWriter(&testTransport{}, src), tv.sign(t, &Message{Address: "/test"}))
+		if len(tv.nonces) != 1 {
+			t.Errorf("expected expired nonces to be removed but got: %d", len(tv.nonces))
+		}
+	})
+}
+
+func TestClient_signer(t *testing.T) {
+	trans := &testTransport{}
+	cli, _ := NewClientWithOptions("", &ClientOptions{
+		Transport: trans,
+		Signer:    NewSigner("k1", []byte("secret")),
+	})
+	_ = cli.EmitMessage("/test")
+	if len(trans.sent) != 1 || trans.sent[0].(*Message).Address != SignedAddress {
+		t.Fatalf("expected signed message but got: %v", trans.sent)
+	}
+	var handled []Package
+	v := NewVerifier(HandlerFunc(func(_ *ResponseWriter, pkg Package) { handled = append(handled, pkg) }),
+		VerifyOptions{Keys: map[string][]byte{"k1": []byte("secret")}})
+	v.HandlePackage(NewResponseWriter(&testTransport{}, nil), trans.sent[0])
+	if len(handled) != 1 || handled[0].(*Message).Address != "/test" {
+		t.Errorf("expected verified message but got: %v", handled)
+	}
+}
@@ -0,0 +1,124 @@
+package gosc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrPacketTooLarge is returned when a packet can not be made to fit in the
+// maximum packet size.
+var ErrPacketTooLarge = errors.New("packet too large")
+
+// SplitBundle splits b into bundles with the same timetag that each encode to
+// at most maxSize bytes. The bundle is returned as is if it already fits.
+// Nested bundles that do not fit are split as well. An error wrapping
+// ErrPacketTooLarge is returned if a single message, or the header of a
+// bundle, does not fit.
+//
+// Note that the messages of a bundle are no longer handled atomically by the
+// receiver once it has been split.
+func SplitBundle(b *Bundle, maxSize int) ([]*Bundle, error) {
+	size, err := encodedSize(b)
+	if err != nil {
+		return nil, err
+	}
+	if size <= maxSize {
+		return []*Bundle{b}, nil
+	}
+
+	s := newBundleSplitter(b, maxSize)
+	if s.header > maxSize {
+		return nil, fmt.Errorf("bundle header of %d bytes does not fit in %d bytes: %w",
+			s.header, maxSize, ErrPacketTooLarge)
+	}
+	for _, msg := range b.Messages {
+		size, err := encodedSize(msg)
+		if err != nil {
+			return nil, err
+		}
+		if s.header+4+size > maxSize {
+			return nil, fmt.Errorf("message %s of %d bytes does not fit in %d bytes: %w",
+				msg.Address, size, maxSize, ErrPacketTooLarge)
+		}
+		s.add(msg, 4+size)
+	}
+	for _, bundle := range b.Bundles {
+		if err := s.addBundle(bundle); err != nil {
+			return nil, err
+		}
+	}
+	return s.finish(), nil
+}
+
+// bundleSplitter collects the elements of a bundle into bundles of at most
+// maxSize bytes.
+type bundleSplitter struct {
+	b           *Bundle
+	maxSize     int
+	header      int
+	bundles     []*Bundle
+	current     *Bundle
+	currentSize int
+}
+
+func newBundleSplitter(b *Bundle, maxSize int) *bundleSplitter {
+	name := b.Name
+	if name == "" {
+		name = "#bundle"
+	}
+	header := len(name) + 1 + getPadBytes(len(name)+1) + 8
+	return &bundleSplitter{
+		b:           b,
+		maxSize:     maxSize,
+		header:      header,
+		current:     &Bundle{Timetag: b.Timetag, Name: b.Name},
+		currentSize: header,
+	}
+}
+
+// add adds pack, which takes size bytes including its size, to the current
+// bundle, starting a new bundle if it does not fit.
+func (s *bundleSplitter) add(pack Package, size int) {
+	if s.currentSize+size > s.maxSize {
+		s.bundles = append(s.bundles, s.current)
+		s.current = &Bundle{Timetag: s.b.Timetag, Name: s.b.Name}
+		s.currentSize = s.header
+	}
+	switch v := pack.(type) {
+	case *Message:
+		s.current.Messages = append(s.current.Messages, v)
+	case *Bundle:
+		s.current.Bundles = append(s.current.Bundles, v)
+	}
+	s.currentSize += size
+}
+
+// addBundle adds the nested bundle, split to fit in a bundle of its own.
+func (s *bundleSplitter) addBundle(bundle *Bundle) error {
+	parts, err := SplitBundle(bundle, s.maxSize-s.header-4)
+	if err != nil {
+		return err
+	}
+	for _, part := range parts {
+		size, err := encodedSize(part)
+		if err != nil {
+			return err
+		}
+		s.add(part, 4+size)
+	}
+	return nil
+}
+
+// finish returns the bundles, including the current bundle if not empty.
+func (s *bundleSplitter) finish() []*Bundle {
+	if len(s.current.Messages)+len(s.current.Bundles) > 0 {
+		s.bundles = append(s.bundles, s.current)
+	}
+	return s.bundles
+}
+
+// encodedSize returns the number of bytes of the encoded Package.
+func encodedSize(pack Package) (int, error) {
+	data, err := encodePackage(pack)
+	return len(data), err
+}
@@ -0,0 +1,100 @@
+package gosc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// meterMessage returns a message of 16 bytes, 20 with its size in a bundle.
+// The header of unnamed bundles is 16 bytes.
+func meterMessage(i int) *Message {
+	return &Message{Address: "/meter", Arguments: []any{int32(i)}}
+}
+
+func TestSplitBundle(t *testing.T) {
+	t.Run("fits", func(t *testing.T) {
+		b := &Bundle{Timetag: 2, Messages: []*Message{meterMessage(0), meterMessage(1)}}
+		bundles, err := SplitBundle(b, 64)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if len(bundles) != 1 || bundles[0] != b {
+			t.Errorf("expected bundle to be returned as is but got: %v", bundles)
+		}
+	})
+	t.Run("messageTooLarge", func(t *testing.T) {
+		b := &Bundle{Messages: []*Message{meterMessage(0), {Address: "/" + strings.Repeat("a", 64)}}}
+		_, err := SplitBundle(b, 64)
+		if !errors.Is(err, ErrPacketTooLarge) {
+			t.Errorf("expected ErrPacketTooLarge but got: %v", err)
+		}
+	})
+	t.Run("headerTooLarge", func(t *testing.T) {
+		bundles, err := SplitBundle(&Bundle{Timetag: 2}, 8)
+		if !errors.Is(err, ErrPacketTooLarge) {
+			t.Errorf("expected ErrPacketTooLarge but got: %v, %v", bundles, err)
+		}
+	})
+	t.Run("nestedHeaderTooLarge", func(t *testing.T) {
+		b := &Bundle{Bundles: []*Bundle{{Name: "#" + strings.Repeat("a", 32)}}, Messages: []*Message{meterMessage(0)}}
+		bundles, err := SplitBundle(b, 48)
+		if !errors.Is(err, ErrPacketTooLarge) {
+			t.Errorf("expected ErrPacketTooLarge but got: %v, %v", bundles, err)
+		}
+	})
+}
+
+func TestSplitBundle_messages(t *testing.T) {
+	b := &Bundle{Timetag: 2}
+	for i := 0; i < 5; i++ {
+		b.Messages = append(b.Messages, meterMessage(i))
+	}
+	bundles, err := SplitBundle(b, 64)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if len(bundles) != 3 {
+		t.Fatalf("expected 3 bundles but got: %d", len(bundles))
+	}
+	next := int32(0)
+	for _, bundle := range bundles {
+		if bundle.Timetag != 2 {
+			t.Errorf("expected timetag to be kept but got: %v", bundle.Timetag)
+		}
+		if size, _ := encodedSize(bundle); size > 64 {
+			t.Errorf("expected bundle of at most 64 bytes but got: %d", size)
+		}
+		for _, m := range bundle.Messages {
+			if v, _ := m.Int32(0); v != next {
+				t.Errorf("expected message %d but got: %d", next, v)
+			}
+			next++
+		}
+	}
+}
+
+func TestSplitBundle_nested(t *testing.T) {
+	b := &Bundle{
+		Timetag:  2,
+		Messages: []*Message{meterMessage(0)},
+		Bundles:  []*Bundle{{Timetag: 3, Messages: []*Message{meterMessage(1), meterMessage(2), meterMessage(3)}}},
+	}
+	bundles, err := SplitBundle(b, 84)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	for _, bundle := range bundles {
+		if size, _ := encodedSize(bundle); size > 84 {
+			t.Errorf("expected bundle of at most 84 bytes but got: %d", size)
+		}
+	}
+	if len(bundles) != 3 {
+		t.Fatalf("expected 3 bundles but got: %d", len(bundles))
+	}
+	for _, bundle := range bundles[1:] {
+		if len(bundle.Bundles) != 1 || bundle.Bundles[0].Timetag != 3 {
+			t.Errorf("expected split nested bundle but got: %v", bundle)
+		}
+	}
+}
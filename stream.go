@@ -0,0 +1,122 @@
+package gosc
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"sync"
+)
+
+// streamAddr is the address of streams that are not network connections.
+type streamAddr struct{}
+
+func (streamAddr) Network() string { return "stream" }
+func (streamAddr) String() string  { return "stream" }
+
+// streamConn sends and receives packages on a single stream.
+type streamConn struct {
+	rwc           io.ReadWriteCloser
+	addr          net.Addr
+	r             *bufio.Reader
+	mu            sync.Mutex
+	framing       Framing
+	maxPacketSize int
+	// metrics of the transport owning the stream, if any.
+	metrics *transportMetrics
+}
+
+func newStreamConn(rwc io.ReadWriteCloser, framing Framing, maxPacketSize int) *streamConn {
+	if maxPacketSize <= 0 {
+		maxPacketSize = DefaultMaxPacketSize
+	}
+	var addr net.Addr = streamAddr{}
+	if conn, ok := rwc.(net.Conn); ok {
+		addr = conn.RemoteAddr()
+	}
+	return &streamConn{
+		rwc:           rwc,
+		addr:          addr,
+		r:             bufio.NewReader(rwc),
+		framing:       framing,
+		maxPacketSize: maxPacketSize,
+	}
+}
+
+// send writes pack as a single frame. Frames are written atomically so
+// several go-routines can send on the same stream.
+func (c *streamConn) send(pack Package) error {
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+	if err := writePackage(pack, w); err != nil {
+		return err
+	}
+	_ = w.Flush()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.framing.writeFrame(c.rwc, buf.Bytes())
+}
+
+// receive reads the next frame. The returned frame error is a DecodeError set
+// when a frame could not be read or decoded but the stream is still usable,
+// err is set when the stream failed and should be closed.
+func (c *streamConn) receive() (pack Package, frameErr, err error) {
+	frame, frameErr, err := c.framing.readFrame(c.r, c.maxPacketSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.metrics != nil {
+		c.metrics.packageReceived(c.addr, len(frame))
+	}
+	if frameErr == nil {
+		pack, frameErr = decodePackage(frame)
+	}
+	if frameErr != nil {
+		return nil, &DecodeError{From: c.addr, Err: frameErr}, nil
+	}
+	return pack, nil, nil
+}
+
+type transportStream struct {
+	transportMetrics
+	sc *streamConn
+}
+
+// NewStreamTransport returns a Transport sending and receiving packages on
+// any byte stream, e.g. a serial device, a pipe to a child process or a
+// net.Conn, using the given framing. The stream is closed by Close.
+func NewStreamTransport(rwc io.ReadWriteCloser, framing Framing) Transport {
+	return newTransportStream(newStreamConn(rwc, framing, DefaultMaxPacketSize))
+}
+
+func newTransportStream(sc *streamConn) *transportStream {
+	t := &transportStream{sc: sc}
+	sc.metrics = &t.transportMetrics
+	return t
+}
+
+// NewSLIPTransport returns a Transport sending and receiving SLIP framed
+// packages on rwc. It is a shorthand for NewStreamTransport(rwc, FramingSLIP).
+func NewSLIPTransport(rwc io.ReadWriteCloser) Transport {
+	return NewStreamTransport(rwc, FramingSLIP)
+}
+
+// Send writes the Package to the stream, the address is ignored.
+func (t *transportStream) Send(pack Package, _ net.Addr) error {
+	return t.sc.send(pack)
+}
+
+// Receive reads the next Package from the stream.
+func (t *transportStream) Receive() (pack Package, from net.Addr, err error) {
+	pack, frameErr, err := t.sc.receive()
+	if err != nil {
+		return nil, t.sc.addr, err
+	}
+	return pack, t.sc.addr, frameErr
+}
+
+// Close closes the stream.
+func (t *transportStream) Close() error {
+	return t.sc.rwc.Close()
+}
@@ -0,0 +1,51 @@
+package gosc
+
+import (
+	"net"
+	"testing"
+)
+
+func TestNewSLIPTransport(t *testing.T) {
+	a, b := net.Pipe()
+	ta, tb := NewSLIPTransport(a), NewSLIPTransport(b)
+	defer ta.(*transportStream).Close()
+	defer tb.(*transportStream).Close()
+
+	go func() {
+		_ = ta.Send(&Message{Address: "/test", Arguments: []any{int32(1)}}, nil)
+	}()
+	pkg, _, err := tb.Receive()
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if v, _ := pkg.(*Message).Int32(0); v != 1 {
+		t.Errorf("expected argument 1 but got: %v", pkg)
+	}
+}
+
+func TestNewStreamTransport(t *testing.T) {
+	for _, framing := range []Framing{FramingSizePrefix, FramingSLIP} {
+		t.Run(framing.String(), func(t *testing.T) {
+			a, b := net.Pipe()
+			srv := NewStreamTransport(a, framing)
+			mux := NewMux(nil)
+			mux.HandleMessageFunc("/hello", func(w *ResponseWriter, msg *Message) {
+				_ = w.Send(&Message{Address: msg.Address, Arguments: []any{"World"}})
+			})
+			go func() {
+				_ = NewServer(&ServerOptions{}).Serve(srv, mux)
+			}()
+			defer srv.(*transportStream).Close()
+
+			cli := NewClientWithTransport(NewStreamTransport(b, framing), nil)
+			defer cli.Close()
+			res, err := cli.CallMessage("/hello")
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			if s, _ := res.StringArg(0); s != "World" {
+				t.Errorf("expected response \"World\" but got: %v", res)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package gosc
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultMaxPacketSize is the largest packet accepted on stream transports
+// unless configured otherwise.
+const DefaultMaxPacketSize = 1 << 20
+
+// ConnState represents the state of a connection to a stream server.
+type ConnState int
+
+// Constants for the connection states reported to TCPOptions.ConnState.
+const (
+	// StateNew is reported when a connection has been accepted.
+	StateNew ConnState = iota
+	// StateClosed is reported when a connection has been closed by either
+	// side.
+	StateClosed
+)
+
+func (c ConnState) String() string {
+	switch c {
+	case StateNew:
+		return "new"
+	case StateClosed:
+		return "closed"
+	}
+	return fmt.Sprintf("ConnState(%d)", int(c))
+}
+
+// TCPOptions is the configuration of TCP transports. The zero value is valid.
+type TCPOptions struct {
+	// Framing of packets on the connections, defaults to FramingSizePrefix.
+	Framing Framing
+	// MaxPacketSize is the largest packet accepted. Larger packets close the
+	// connection with FramingSizePrefix and are skipped with FramingSLIP.
+	// Defaults to DefaultMaxPacketSize.
+	MaxPacketSize int
+	// ConnState is called when a server accepts a connection and when it is
+	// closed.
+	ConnState func(addr net.Addr, state ConnState)
+	// LocalAddress is the address clients connect from, an ephemeral port is
+	// used if empty.
+	LocalAddress string
+	// TLSConfig makes the connections use TLS, if not nil. Servers need
+	// Certificates and set ClientAuth to require client certificates for
+	// mutual TLS. Servers drop connections failing the handshake.
+	TLSConfig *tls.Config
+}
+
+// NewTCPTransport returns a TCP Transport for clients connected to the
+// server at address. The opts can be nil to use the defaults.
+func NewTCPTransport(address string, opts *TCPOptions) (Transport, error) {
+	if opts == nil {
+		opts = &TCPOptions{}
+	}
+	dialer := net.Dialer{}
+	if opts.LocalAddress != "" {
+		laddr, err := net.ResolveTCPAddr("tcp", opts.LocalAddress)
+		if err != nil {
+			return nil, err
+		}
+		dialer.LocalAddr = laddr
+	}
+	var conn net.Conn
+	var err error
+	if opts.TLSConfig != nil {
+		tlsDialer := tls.Dialer{NetDialer: &dialer, Config: opts.TLSConfig}
+		conn, err = tlsDialer.Dial("tcp", address)
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newTransportStream(newStreamConn(conn, opts.Framing, opts.MaxPacketSize)), nil
+}
+
+// minAcceptDelay and maxAcceptDelay bound the time waited before accepting
+// again after an error.
+const (
+	minAcceptDelay = 5 * time.Millisecond
+	maxAcceptDelay = time.Second
+)
+
+// received is a Package, or error, received on one of the connections of a
+// stream server.
+type received struct {
+	pack Package
+	from net.Addr
+	err  error
+}
+
+type transportTCPListen struct {
+	transportMetrics
+	ln       net.Listener
+	opts     TCPOptions
+	mu       sync.Mutex
+	conns    map[string]*streamConn
+	received chan received
+	done     chan struct{}
+	doneOnce sync.Once
+	err      error
+}
+
+// NewTCPListen returns a TCP Transport for servers listening on address.
+// Every accepted connection is read in a separate go-routine and packages
+// are sent back on the connection matching the address. The opts can be nil
+// to use the defaults.
+func NewTCPListen(address string, opts *TCPOptions) (Transport, error) {
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	if opts != nil && opts.TLSConfig != nil {
+		ln = tls.NewListener(ln, opts.TLSConfig)
+	}
+	return newStreamListen(ln, opts), nil
+}
+
+func newStreamListen(ln net.Listener, opts *TCPOptions) *transportTCPListen {
+	if opts == nil {
+		opts = &TCPOptions{}
+	}
+	t := &transportTCPListen{
+		ln:       ln,
+		opts:     *opts,
+		conns:    map[string]*streamConn{},
+		received: make(chan received),
+		done:     make(chan struct{}),
+	}
+	go t.accept()
+	return t
+}
+
+// Send writes the Package to the connection with the remote address addr.
+func (t *transportTCPListen) Send(pack Package, addr net.Addr) error {
+	if addr == nil {
+		return fmt.Errorf("no connection to %v", addr)
+	}
+	t.mu.Lock()
+	sc, ok := t.conns[addr.String()]
+	t.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no connection to %v", addr)
+	}
+	return sc.send(pack)
+}
+
+// Receive returns the next Package received on any of the connections.
+func (t *transportTCPListen) Receive() (pack Package, from net.Addr, err error) {
+	select {
+	case r := <-t.received:
+		return r.pack, r.from, r.err
+	case <-t.done:
+		return nil, nil, t.err
+	}
+}
+
+// Close stops listening and closes all connections.
+func (t *transportTCPListen) Close() error {
+	t.stop(net.ErrClosed)
+	err := t.ln.Close()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, sc := range t.conns {
+		_ = sc.rwc.Close()
+	}
+	return err
+}
+
+// stop makes Receive return err.
+func (t *transportTCPListen) stop(err error) {
+	t.doneOnce.Do(func() {
+		t.err = err
+		close(t.done)
+	})
+}
+
+// accept serves the accepted connections until the listener is closed. Other
+// errors, e.g. running out of file descriptors, are returned by Receive as an
+// AcceptError and retried with a backoff like net/http does.
+func (t *transportTCPListen) accept() {
+	var delay time.Duration
+	for {
+		conn, err := t.ln.Accept()
+		if err == nil {
+			delay = 0
+			go t.serve(conn)
+			continue
+		}
+		if errors.Is(err, net.ErrClosed) {
+			t.stop(err)
+			return
+		}
+		if delay == 0 {
+			delay = minAcceptDelay
+		} else {
+			delay *= 2
+		}
+		if delay > maxAcceptDelay {
+			delay = maxAcceptDelay
+		}
+		select {
+		case t.received <- received{err: &AcceptError{Err: err, Delay: delay}}:
+		case <-t.done:
+			return
+		}
+		select {
+		case <-time.After(delay):
+		case <-t.done:
+			return
+		}
+	}
+}
+
+func (t *transportTCPListen) serve(conn net.Conn) {
+	addr := conn.RemoteAddr()
+	if err := handshake(conn); err != nil {
+		_ = conn.Close()
+		return
+	}
+	sc := newStreamConn(conn, t.opts.Framing, t.opts.MaxPacketSize)
+	sc.metrics = &t.transportMetrics
+	t.mu.Lock()
+	select {
+	case <-t.done:
+		t.mu.Unlock()
+		_ = conn.Close()
+		return
+	default:
+		t.conns[addr.String()] = sc
+	}
+	t.mu.Unlock()
+	t.setState(addr, StateNew)
+
+	defer func() {
+		t.mu.Lock()
+		delete(t.conns, addr.String())
+		t.mu.Unlock()
+		_ = conn.Close()
+		t.setState(addr, StateClosed)
+	}()
+
+	for {
+		pack, frameErr, err := sc.receive()
+		if err != nil {
+			return
+		}
+		select {
+		case t.received <- received{pack: pack, from: addr, err: frameErr}:
+		case <-t.done:
+			return
+		}
+	}
+}
+
+func (t *transportTCPListen) setState(addr net.Addr, state ConnState) {
+	if t.opts.ConnState != nil {
+		t.opts.ConnState(addr, state)
+	}
+}
@@ -0,0 +1,175 @@
+package gosc
+
+import (
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestNewTCPListen(t *testing.T) {
+	states := make(chan ConnState, 2)
+	trans, err := NewTCPListen("127.0.0.1:0", &TCPOptions{
+		ConnState: func(_ net.Addr, state ConnState) {
+			states <- state
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	listen := trans.(*transportTCPListen)
+	defer listen.Close()
+
+	cli, err := NewTCPTransport(listen.ln.Addr().String(), nil)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if state := <-states; state != StateNew {
+		t.Errorf("expected state new but got: %v", state)
+	}
+
+	_ = cli.Send(&Message{Address: "/ping", Arguments: []any{int32(1)}}, nil)
+	pkg, from, err := trans.Receive()
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if msg := pkg.(*Message); msg.Address != "/ping" {
+		t.Errorf("expected /ping but got: %s", msg.Address)
+	}
+
+	_ = trans.Send(&Message{Address: "/pong"}, from)
+	pkg, _, err = cli.Receive()
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if msg := pkg.(*Message); msg.Address != "/pong" {
+		t.Errorf("expected /pong but got: %s", msg.Address)
+	}
+
+	_ = cli.(*transportStream).Close()
+	select {
+	case state := <-states:
+		if state != StateClosed {
+			t.Errorf("expected state closed but got: %v", state)
+		}
+	case <-time.After(time.Second):
+		t.Error("expected connection to be closed")
+	}
+}
+
+func TestNewTCPTransport(t *testing.T) {
+	t.Run("malformedAddress", func(t *testing.T) {
+		_, err := NewTCPTransport("512.abc:001", nil)
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+}
+
+func Test_transportTCPListen_Send(t *testing.T) {
+	trans, err := NewTCPListen("127.0.0.1:0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer trans.(*transportTCPListen).Close()
+	if err := trans.Send(&Message{Address: "/test"}, nil); err == nil {
+		t.Error("expected error sending to nil address")
+	}
+	if err := trans.Send(&Message{Address: "/test"}, &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1}); err == nil {
+		t.Error("expected error sending to unknown address")
+	}
+}
+
+func Test_transportTCPListen_Close(t *testing.T) {
+	trans, _ := NewTCPListen("127.0.0.1:0", nil)
+	_ = trans.(*transportTCPListen).Close()
+	_, _, err := trans.Receive()
+	if err == nil {
+		t.Error("expected error but none given")
+	}
+}
+
+func TestNewTCPListen_slip(t *testing.T) {
+	trans, err := NewTCPListen("127.0.0.1:0", &TCPOptions{Framing: FramingSLIP})
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	listen := trans.(*transportTCPListen)
+	defer listen.Close()
+
+	cli, err := NewTCPTransport(listen.ln.Addr().String(), &TCPOptions{Framing: FramingSLIP})
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer cli.(*transportStream).Close()
+	_ = cli.Send(&Message{Address: "/ping", Arguments: []any{[]byte{slipEnd, slipEsc}}}, nil)
+	pkg, _, err := trans.Receive()
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if b, _ := pkg.(*Message).Blob(0); len(b) != 2 || b[0] != slipEnd || b[1] != slipEsc {
+		t.Errorf("expected escaped blob to be received but got: %v", pkg)
+	}
+}
+
+// flakyListener fails to accept errs times before accepting conn, and then
+// blocks until closed.
+type flakyListener struct {
+	errs   int
+	conn   net.Conn
+	closed chan struct{}
+}
+
+func (l *flakyListener) Accept() (net.Conn, error) {
+	if l.errs > 0 {
+		l.errs--
+		return nil, syscall.EMFILE
+	}
+	if conn := l.conn; conn != nil {
+		l.conn = nil
+		return conn, nil
+	}
+	<-l.closed
+	return nil, net.ErrClosed
+}
+
+func (l *flakyListener) Close() error {
+	close(l.closed)
+	return nil
+}
+
+func (l *flakyListener) Addr() net.Addr {
+	return &net.TCPAddr{}
+}
+
+func Test_transportTCPListen_accept(t *testing.T) {
+	a, b := net.Pipe()
+	ln := &flakyListener{errs: 3, conn: a, closed: make(chan struct{})}
+	trans := newStreamListen(ln, nil)
+	go func() { _ = NewStreamTransport(b, FramingSizePrefix).Send(&Message{Address: "/test"}, nil) }()
+	for i, delay := range []time.Duration{minAcceptDelay, 2 * minAcceptDelay, 4 * minAcceptDelay} {
+		_, _, err := trans.Receive()
+		var acceptErr *AcceptError
+		if !errors.As(err, &acceptErr) || !errors.Is(err, syscall.EMFILE) {
+			t.Fatalf("expected AcceptError %d wrapping EMFILE but got: %v", i, err)
+		}
+		if acceptErr.Delay != delay {
+			t.Errorf("expected delay %v but got: %v", delay, acceptErr.Delay)
+		}
+		if !isTransientError(err) {
+			t.Errorf("expected AcceptError to be transient")
+		}
+	}
+	pkg, _, err := trans.Receive()
+	if err != nil {
+		t.Fatalf("expected connection to be accepted after errors but got: %v", err)
+	}
+	if pkg.(*Message).Address != "/test" {
+		t.Errorf("expected /test but got: %v", pkg)
+	}
+	_ = trans.Close()
+	if _, _, err := trans.Receive(); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("expected net.ErrClosed but got: %v", err)
+	}
+}
@@ -0,0 +1,514 @@
+package gosc
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// The text format of a Message is the address followed by the type tag string
+// and one value for each argument carrying data, separated by whitespace:
+//
+//	/synth/freq ,fi 440.0 3
+//
+// Strings are double-quoted, chars single-quoted, blobs are hex bytes prefixed
+// with "0x" and timetags, RGBA colors and MIDI messages are written as hex
+// numbers. Arguments of type T, F, N and I, as well as array brackets, only
+// appear in the type tag string.
+//
+// A Bundle is written as its name and timetag followed by its elements, each
+// enclosed in braces:
+//
+//	#bundle 0x0000000000000001 { /a ,i 1 } { /b ,s "x" }
+
+// ParseMessage parses a Message written in the text format. The type tag
+// string may be left out, in which case the argument types are inferred:
+// integers are int32 (int64 if they don't fit), numbers with a decimal point
+// or exponent are float32, quoted text is string or Char and true, false, nil
+// and infinitum are the T, F, N and I arguments. Unquoted words are strings.
+func ParseMessage(s string) (*Message, error) {
+	pkg, err := ParsePackage(s)
+	if err != nil {
+		return nil, err
+	}
+	msg, ok := pkg.(*Message)
+	if !ok {
+		return nil, errors.New("text is a bundle, not a message")
+	}
+	return msg, nil
+}
+
+// ParsePackage parses a Message or Bundle written in the text format. See
+// ParseMessage.
+func ParsePackage(s string) (Package, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &textParser{tokens: tokens}
+	pkg, err := p.parsePackage()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q after package", p.peek())
+	}
+	return pkg, nil
+}
+
+// String returns the Message in the text format.
+func (m *Message) String() string {
+	sb := strings.Builder{}
+	formatMessage(&sb, m)
+	return sb.String()
+}
+
+// Format implements fmt.Formatter. The verbs %v and %s write the Message in
+// the text format and %q writes it as a quoted string.
+func (m *Message) Format(f fmt.State, verb rune) {
+	formatPackage(f, verb, m)
+}
+
+// String returns the Bundle in the text format.
+func (b *Bundle) String() string {
+	sb := strings.Builder{}
+	formatBundle(&sb, b, "")
+	return sb.String()
+}
+
+// Format implements fmt.Formatter. The verbs %v and %s write the Bundle in the
+// text format and %q writes it as a quoted string. With %+v every element is
+// written on a separate, indented line.
+func (b *Bundle) Format(f fmt.State, verb rune) {
+	formatPackage(f, verb, b)
+}
+
+func formatPackage(f fmt.State, verb rune, pkg Package) {
+	sb := strings.Builder{}
+	switch v := pkg.(type) {
+	case *Message:
+		formatMessage(&sb, v)
+	case *Bundle:
+		indent := ""
+		if f.Flag('+') {
+			indent = "\n"
+		}
+		formatBundle(&sb, v, indent)
+	}
+	switch verb {
+	case 'v', 's':
+		_, _ = f.Write([]byte(sb.String()))
+	case 'q':
+		_, _ = f.Write([]byte(strconv.Quote(sb.String())))
+	default:
+		_, _ = fmt.Fprintf(f, "%%!%c(%T)", verb, pkg)
+	}
+}
+
+func formatMessage(sb *strings.Builder, m *Message) {
+	sb.WriteString(m.Address)
+	if len(m.Arguments) == 0 {
+		return
+	}
+	sb.WriteByte(' ')
+	sb.WriteString(m.TypeTags())
+	formatArguments(sb, m.Arguments)
+}
+
+func formatArguments(sb *strings.Builder, args []any) {
+	for _, a := range args {
+		if arr, ok := a.([]any); ok {
+			formatArguments(sb, arr)
+			continue
+		}
+		if str, ok := formatValue(a); ok {
+			sb.WriteByte(' ')
+			sb.WriteString(str)
+		}
+	}
+}
+
+// formatBundle writes b to sb. If indent is not empty every element is
+// written on a new line prefixed with indent and a tab.
+func formatBundle(sb *strings.Builder, b *Bundle, indent string) {
+	name := b.Name
+	if name == "" {
+		name = "#bundle"
+	}
+	sb.WriteString(name)
+	sb.WriteByte(' ')
+	sb.WriteString(formatTimetag(b.Timetag))
+
+	sep := " "
+	inner := ""
+	if indent != "" {
+		inner = indent + "\t"
+		sep = inner
+	}
+	for _, m := range b.Messages {
+		sb.WriteString(sep + "{ ")
+		formatMessage(sb, m)
+		sb.WriteString(" }")
+	}
+	for _, nb := range b.Bundles {
+		sb.WriteString(sep + "{ ")
+		formatBundle(sb, nb, inner)
+		sb.WriteString(" }")
+	}
+}
+
+// formatValue returns the text of a single argument. The boolean is false for
+// arguments only represented by their type tag.
+func formatValue(a any) (string, bool) {
+	switch v := a.(type) {
+	case int32:
+		return strconv.FormatInt(int64(v), 10), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case float32:
+		return formatFloat(float64(v), 32), true
+	case float64:
+		return formatFloat(v, 64), true
+	case string:
+		return strconv.Quote(v), true
+	case Symbol:
+		return strconv.Quote(string(v)), true
+	case Char:
+		return strconv.QuoteRune(rune(v)), true
+	case []byte:
+		return "0x" + hex.EncodeToString(v), true
+	case Timetag:
+		return formatTimetag(v), true
+	case color.RGBA:
+		return fmt.Sprintf("0x%02x%02x%02x%02x", v.R, v.G, v.B, v.A), true
+	case MIDI:
+		return fmt.Sprintf("0x%02x%02x%02x%02x", v.Port, v.Status, v.Data1, v.Data2), true
+	}
+	return "", false
+}
+
+// formatFloat formats f so that it is always parsed back as a float, e.g. 440
+// is written as 440.0.
+func formatFloat(f float64, bitSize int) string {
+	str := strconv.FormatFloat(f, 'g', -1, bitSize)
+	if !strings.ContainsAny(str, ".eIN") {
+		str += ".0"
+	}
+	return str
+}
+
+func formatTimetag(tt Timetag) string {
+	return fmt.Sprintf("0x%016x", uint64(tt))
+}
+
+// tokenize splits s into whitespace separated tokens. Quoted strings are kept
+// as single tokens including the quotes and braces are always tokens of their
+// own.
+func tokenize(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '{' || c == '}':
+			tokens = append(tokens, s[i:i+1])
+			i++
+		case c == '"' || c == '\'':
+			j, err := quoteEnd(s, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		default:
+			j := wordEnd(s, i)
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// quoteEnd returns the offset after the closing quote of the quoted string
+// starting at offset i of s, skipping escaped characters.
+func quoteEnd(s string, i int) (int, error) {
+	j := i + 1
+	for ; j < len(s) && s[j] != s[i]; j++ {
+		if s[j] == '\\' {
+			j++
+		}
+	}
+	if j >= len(s) {
+		return 0, fmt.Errorf("unterminated quote at offset %d", i)
+	}
+	return j + 1, nil
+}
+
+// wordEnd returns the offset after the unquoted token starting at offset i of
+// s.
+func wordEnd(s string, i int) int {
+	j := i
+	for j < len(s) && !strings.ContainsRune(" \t\n\r{}\"'", rune(s[j])) {
+		j++
+	}
+	return j
+}
+
+type textParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *textParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *textParser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *textParser) next() (string, error) {
+	if p.done() {
+		return "", errors.New("unexpected end of text")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+// atValueEnd reports whether there are no more argument values to read for
+// the current message.
+func (p *textParser) atValueEnd() bool {
+	return p.done() || p.peek() == "}"
+}
+
+func (p *textParser) parsePackage() (Package, error) {
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case strings.HasPrefix(tok, "/"):
+		return p.parseMessage(tok)
+	case strings.HasPrefix(tok, "#"):
+		return p.parseBundle(tok)
+	}
+	return nil, fmt.Errorf("package must start with '/' or '#', got %q", tok)
+}
+
+func (p *textParser) parseBundle(name string) (*Bundle, error) {
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	tt, err := parseTimetag(tok)
+	if err != nil {
+		return nil, err
+	}
+	bundle := &Bundle{
+		Timetag:  tt,
+		Messages: make([]*Message, 0),
+		Bundles:  make([]*Bundle, 0),
+		Name:     name,
+	}
+	for p.peek() == "{" {
+		p.pos++
+		pkg, err := p.parsePackage()
+		if err != nil {
+			return nil, err
+		}
+		if tok, err := p.next(); err != nil || tok != "}" {
+			return nil, fmt.Errorf("expected '}' after bundle element, got %q", tok)
+		}
+		switch v := pkg.(type) {
+		case *Message:
+			bundle.Messages = append(bundle.Messages, v)
+		case *Bundle:
+			bundle.Bundles = append(bundle.Bundles, v)
+		}
+	}
+	return bundle, nil
+}
+
+func (p *textParser) parseMessage(address string) (*Message, error) {
+	var args []any
+	var err error
+	if strings.HasPrefix(p.peek(), ",") {
+		tags, _ := p.next()
+		args, err = p.parseTypedArguments(tags)
+	} else {
+		args, err = p.parseInferredArguments()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Message{Address: address, Arguments: args}, nil
+}
+
+// parseInferredArguments parses the values of a message without type tags.
+func (p *textParser) parseInferredArguments() ([]any, error) {
+	args := make([]any, 0)
+	for !p.atValueEnd() {
+		tok, _ := p.next()
+		val, err := inferValue(tok)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, val)
+	}
+	return args, nil
+}
+
+// parseTypedArguments parses the values of a message with the type tags tags.
+func (p *textParser) parseTypedArguments(tags string) ([]any, error) {
+	args := make([]any, 0)
+	var parents [][]any
+	for _, tt := range tags[1:] {
+		switch typeTag(tt) {
+		case TypeTagArrayStart:
+			parents = append(parents, args)
+			args = make([]any, 0)
+			continue
+		case TypeTagArrayStop:
+			if len(parents) == 0 {
+				return nil, errors.New("typetag array end without start")
+			}
+			args = append(parents[len(parents)-1], args)
+			parents = parents[:len(parents)-1]
+			continue
+		}
+		val, err := p.parseTaggedValue(typeTag(tt))
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, val)
+	}
+	if len(parents) != 0 {
+		return nil, errors.New("typetag array start without end")
+	}
+	if !p.atValueEnd() {
+		return nil, fmt.Errorf("unexpected value %q, type tags are %s", p.peek(), tags)
+	}
+	return args, nil
+}
+
+// parseTaggedValue returns the value of the type tag tt, parsing the next
+// token for type tags that carry data.
+func (p *textParser) parseTaggedValue(tt typeTag) (any, error) {
+	switch tt {
+	case TypeTagTrue:
+		return true, nil
+	case TypeTagFalse:
+		return false, nil
+	case TypeTagNil:
+		return nil, nil
+	case TypeTagInfinite:
+		return Infinitum{}, nil
+	}
+	if p.atValueEnd() {
+		return nil, fmt.Errorf("missing value for type tag '%c'", tt)
+	}
+	tok, _ := p.next()
+	return parseValue(tt, tok)
+}
+
+// parseValue parses the text of a single argument with the type tag tt.
+func parseValue(tt typeTag, tok string) (any, error) {
+	switch tt {
+	case TypeTagInt32:
+		i, err := strconv.ParseInt(tok, 0, 32)
+		return int32(i), err
+	case TypeTagBigInt:
+		return strconv.ParseInt(tok, 0, 64)
+	case TypeTagFloat32:
+		f, err := strconv.ParseFloat(tok, 32)
+		return float32(f), err
+	case TypeTagDouble:
+		return strconv.ParseFloat(tok, 64)
+	case TypeTagString:
+		return parseString(tok)
+	case TypeTagStringAlternate:
+		str, err := parseString(tok)
+		return Symbol(str), err
+	case TypeTagChar:
+		return parseChar(tok)
+	case TypeTagBlob:
+		if !strings.HasPrefix(tok, "0x") {
+			return nil, fmt.Errorf("blob %q must start with 0x", tok)
+		}
+		return hex.DecodeString(tok[2:])
+	case TypeTagTimetag:
+		return parseTimetag(tok)
+	case TypeTagRGBA:
+		v, err := strconv.ParseUint(tok, 0, 32)
+		return color.RGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, err
+	case TypeTagMIDI:
+		v, err := strconv.ParseUint(tok, 0, 32)
+		return MIDI{Port: uint8(v >> 24), Status: uint8(v >> 16), Data1: uint8(v >> 8), Data2: uint8(v)}, err
+	}
+	return nil, fmt.Errorf("unknown tag type '%c'", tt)
+}
+
+// inferValue parses the text of a single argument without a known type tag.
+func inferValue(tok string) (any, error) {
+	switch tok {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "nil":
+		return nil, nil
+	case "infinitum":
+		return Infinitum{}, nil
+	}
+	switch tok[0] {
+	case '"':
+		return parseString(tok)
+	case '\'':
+		return parseChar(tok)
+	}
+	if i, err := strconv.ParseInt(tok, 0, 32); err == nil {
+		return int32(i), nil
+	}
+	if i, err := strconv.ParseInt(tok, 0, 64); err == nil {
+		return i, nil
+	}
+	if strings.ContainsAny(tok, "0123456789") {
+		if f, err := strconv.ParseFloat(tok, 32); err == nil {
+			return float32(f), nil
+		}
+	}
+	return tok, nil
+}
+
+func parseString(tok string) (string, error) {
+	if strings.HasPrefix(tok, "\"") {
+		return strconv.Unquote(tok)
+	}
+	return tok, nil
+}
+
+func parseChar(tok string) (Char, error) {
+	str, err := strconv.Unquote(tok)
+	if err != nil {
+		return 0, err
+	}
+	r := []rune(str)
+	if len(r) != 1 {
+		return 0, fmt.Errorf("char %s must be a single character", tok)
+	}
+	return Char(r[0]), nil
+}
+
+func parseTimetag(tok string) (Timetag, error) {
+	v, err := strconv.ParseUint(tok, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timetag %q: %w", tok, err)
+	}
+	return Timetag(v), nil
+}
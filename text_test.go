@@ -0,0 +1,144 @@
+package gosc
+
+import (
+	"fmt"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+func TestParseMessage(t *testing.T) {
+	t.Run("withTypeTags", func(t *testing.T) {
+		msg, err := ParseMessage(`/synth/freq ,fi 440.0 3`)
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+		expected := []any{float32(440), int32(3)}
+		if msg.Address != "/synth/freq" || !reflect.DeepEqual(msg.Arguments, expected) {
+			t.Errorf("expected /synth/freq %v but got: %s %v", expected, msg.Address, msg.Arguments)
+		}
+	})
+	t.Run("inferredTypes", func(t *testing.T) {
+		msg, err := ParseMessage(`/test 1 2.5 "a b" word 'c' true nil 5000000000`)
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+		expected := []any{int32(1), float32(2.5), "a b", "word", Char('c'), true, nil, int64(5000000000)}
+		if !reflect.DeepEqual(msg.Arguments, expected) {
+			t.Errorf("expected %v but got: %v", expected, msg.Arguments)
+		}
+	})
+	t.Run("arrays", func(t *testing.T) {
+		msg, err := ParseMessage(`/test ,i[sT]F 1 "a"`)
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+		expected := []any{int32(1), []any{"a", true}, false}
+		if !reflect.DeepEqual(msg.Arguments, expected) {
+			t.Errorf("expected %v but got: %v", expected, msg.Arguments)
+		}
+	})
+	t.Run("missingValue", func(t *testing.T) {
+		_, err := ParseMessage(`/test ,ii 1`)
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("extraValue", func(t *testing.T) {
+		_, err := ParseMessage(`/test ,i 1 2`)
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("wrongValue", func(t *testing.T) {
+		_, err := ParseMessage(`/test ,i abc`)
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("unterminatedQuote", func(t *testing.T) {
+		_, err := ParseMessage(`/test ,s "abc`)
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("bundle", func(t *testing.T) {
+		_, err := ParseMessage(`#bundle 1 { /a }`)
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+}
+
+func TestParsePackage(t *testing.T) {
+	t.Run("bundle", func(t *testing.T) {
+		pkg, err := ParsePackage(`#bundle 0x1 { /a ,i 1 } { #bundle 0x2 { /b } } { /c ,s "}" }`)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		b := pkg.(*Bundle)
+		if b.Timetag != 1 || len(b.Messages) != 2 || len(b.Bundles) != 1 {
+			t.Errorf("expected bundle with 2 messages and 1 bundle but got: %v", b)
+		}
+		if b.Messages[1].Arguments[0] != "}" {
+			t.Errorf("expected quoted brace to be a string but got: %v", b.Messages[1].Arguments)
+		}
+	})
+	t.Run("unclosedElement", func(t *testing.T) {
+		_, err := ParsePackage(`#bundle 0x1 { /a`)
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("notPackage", func(t *testing.T) {
+		_, err := ParsePackage(`test`)
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+}
+
+func TestMessage_String(t *testing.T) {
+	msg := &Message{
+		Address: "/test",
+		Arguments: []any{
+			int32(1), float32(440), "a \"b\"", []byte{0xde, 0xad}, int64(2), Timetag(1), float64(0.5),
+			Symbol("s"), Char('c'), color.RGBA{R: 1, G: 2, B: 3, A: 4}, MIDI{Status: 0x90, Data1: 60, Data2: 127},
+			true, false, nil, Infinitum{}, []any{int32(3)},
+		},
+	}
+	expected := `/test ,ifsbhtdScrmTFNI[i] 1 440.0 "a \"b\"" 0xdead 2 0x0000000000000001 0.5 "s" 'c' 0x01020304 0x00903c7f 3`
+	if str := msg.String(); str != expected {
+		t.Errorf("expected %s but got: %s", expected, str)
+	}
+	res, err := ParseMessage(msg.String())
+	if err != nil {
+		t.Errorf("expected no error but got: %v", err)
+	}
+	if !reflect.DeepEqual(res, msg) {
+		t.Errorf("expected %v to be parsed back but got: %v", msg, res)
+	}
+}
+
+func TestBundle_Format(t *testing.T) {
+	b := &Bundle{
+		Timetag:  Immediately,
+		Messages: []*Message{{Address: "/a", Arguments: []any{int32(1)}}},
+		Bundles:  []*Bundle{{Timetag: 2, Messages: []*Message{{Address: "/b"}}}},
+	}
+	t.Run("singleLine", func(t *testing.T) {
+		expected := "#bundle 0x0000000000000001 { /a ,i 1 } { #bundle 0x0000000000000002 { /b } }"
+		if str := fmt.Sprintf("%v", b); str != expected {
+			t.Errorf("expected %s but got: %s", expected, str)
+		}
+	})
+	t.Run("multiLine", func(t *testing.T) {
+		expected := "#bundle 0x0000000000000001\n\t{ /a ,i 1 }\n\t{ #bundle 0x0000000000000002\n\t\t{ /b } }"
+		if str := fmt.Sprintf("%+v", b); str != expected {
+			t.Errorf("expected %q but got: %q", expected, str)
+		}
+		if _, err := ParsePackage(fmt.Sprintf("%+v", b)); err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+}
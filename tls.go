@@ -0,0 +1,64 @@
+package gosc
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+)
+
+// tlsHandshakeTimeout is the time servers wait for the TLS handshake of an
+// accepted connection.
+const tlsHandshakeTimeout = 10 * time.Second
+
+// TLSTransport is implemented by transports using TLS, to expose the
+// certificates of the peers to handlers, see ResponseWriter.TLS.
+type TLSTransport interface {
+	Transport
+	// ConnectionState returns the state of the TLS connection to addr, false
+	// if there is none.
+	ConnectionState(addr net.Addr) (tls.ConnectionState, bool)
+}
+
+// connectionState returns the state of the stream if it is a TLS connection.
+func (c *streamConn) connectionState() (tls.ConnectionState, bool) {
+	conn, ok := c.rwc.(*tls.Conn)
+	if !ok {
+		return tls.ConnectionState{}, false
+	}
+	return conn.ConnectionState(), true
+}
+
+// ConnectionState returns the state of the stream if it is a TLS connection,
+// the address is ignored.
+func (t *transportStream) ConnectionState(_ net.Addr) (tls.ConnectionState, bool) {
+	return t.sc.connectionState()
+}
+
+// ConnectionState returns the state of the TLS connection with the remote
+// address addr.
+func (t *transportTCPListen) ConnectionState(addr net.Addr) (tls.ConnectionState, bool) {
+	if addr == nil {
+		return tls.ConnectionState{}, false
+	}
+	t.mu.Lock()
+	sc, ok := t.conns[addr.String()]
+	t.mu.Unlock()
+	if !ok {
+		return tls.ConnectionState{}, false
+	}
+	return sc.connectionState()
+}
+
+// handshake completes the TLS handshake of conn, if it is a TLS connection,
+// so that the peer certificates are known before the first package.
+func handshake(conn net.Conn) error {
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return nil
+	}
+	_ = conn.SetDeadline(time.Now().Add(tlsHandshakeTimeout))
+	if err := tlsConn.Handshake(); err != nil {
+		return err
+	}
+	return conn.SetDeadline(time.Time{})
+}
@@ -0,0 +1,162 @@
+package gosc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCA issues certificates for tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+func (ca *testCA) issue(t *testing.T, name string, usage x509.ExtKeyUsage) tls.Certificate {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestServer_ListenAndServe_tls(t *testing.T) {
+	ca := newTestCA(t)
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	address := ln.Addr().String()
+	_ = ln.Close()
+
+	peers := make(chan string, 1)
+	mux := NewMux(nil)
+	mux.HandleMessageFunc("/hello", func(w *ResponseWriter, msg *Message) {
+		if cert := w.PeerCertificate(); cert != nil {
+			peers <- cert.Subject.CommonName
+		} else {
+			peers <- ""
+		}
+		_ = w.Send(&Message{Address: "/hello"})
+	})
+	srv := NewServer(&ServerOptions{TLSConfig: &tls.Config{
+		Certificates: []tls.Certificate{ca.issue(t, "server", x509.ExtKeyUsageServerAuth)},
+		ClientCAs:    ca.pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}})
+	errs := make(chan error, 1)
+	go func() { errs <- srv.ListenAndServe("tls://"+address, mux) }()
+	defer func() {
+		_ = srv.Shutdown()
+		if err := <-errs; err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	}()
+
+	dial := func(config *tls.Config) (*Client, error) {
+		var cli *Client
+		var err error
+		for i := 0; i < 100; i++ {
+			cli, err = NewClientWithOptions("tls://"+address, &ClientOptions{TLSConfig: config})
+			if err == nil {
+				return cli, nil
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		return nil, err
+	}
+	t.Run("mutual", func(t *testing.T) {
+		cli, err := dial(&tls.Config{
+			RootCAs:      ca.pool,
+			Certificates: []tls.Certificate{ca.issue(t, "foh", x509.ExtKeyUsageClientAuth)},
+		})
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		defer cli.Close()
+		if _, err := cli.CallMessage("/hello"); err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if peer := <-peers; peer != "foh" {
+			t.Errorf("expected peer foh but got: %q", peer)
+		}
+	})
+	t.Run("noClientCertificate", func(t *testing.T) {
+		// With TLS 1.3 the client completes its side of the handshake and the
+		// server rejects the missing certificate afterwards.
+		cli, err := NewClientWithOptions("tls://"+address, &ClientOptions{TLSConfig: &tls.Config{
+			RootCAs:    ca.pool,
+			MinVersion: tls.VersionTLS13,
+		}})
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		defer cli.Close()
+		_ = cli.EmitMessage("/hello")
+		<-cli.Done()
+		if err := cli.Err(); err == nil || !strings.Contains(err.Error(), "certificate required") {
+			t.Errorf("expected connection to fail with handshake error but got: %v", err)
+		}
+		if len(peers) != 0 {
+			t.Errorf("expected handler not to run but got peer: %q", <-peers)
+		}
+	})
+	t.Run("untrustedServer", func(t *testing.T) {
+		if _, err := NewClientWithOptions("tls://"+address, nil); err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+}
+
+func TestServer_listenTransport_tls(t *testing.T) {
+	srv := NewServer(&ServerOptions{})
+	if _, err := srv.listenTransport("tls://127.0.0.1:0"); err == nil {
+		t.Error("expected error but none given")
+	}
+}
+
+func TestResponseWriter_TLS(t *testing.T) {
+	if state := NewResponseWriter(&testTransport{}, nil).TLS(); state != nil {
+		t.Errorf("expected no TLS state but got: %v", state)
+	}
+	if cert := NewResponseWriter(&testTransport{}, nil).PeerCertificate(); cert != nil {
+		t.Errorf("expected no peer certificate but got: %v", cert)
+	}
+}
@@ -1,7 +1,13 @@
 package gosc
 
 import (
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"net"
+	"strings"
+	"syscall"
+	"time"
 )
 
 // Transport interface describes the transportation used by the Client and
@@ -10,3 +16,131 @@ type Transport interface {
 	Send(pack Package, addr net.Addr) error
 	Receive() (pack Package, from net.Addr, err error)
 }
+
+// DecodeError is returned by transports when a received packet could not be
+// decoded. The transport can still be used to receive the next packet.
+type DecodeError struct {
+	// From is the sender of the packet.
+	From net.Addr
+	// Err is the error of decoding the packet.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decoding packet from %v: %v", e.From, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// AcceptError is returned by stream server transports when accepting a
+// connection failed, e.g. because the process ran out of file descriptors.
+// The transport accepts again after Delay.
+type AcceptError struct {
+	// Err is the error of accepting the connection.
+	Err error
+	// Delay is the time waited before accepting again.
+	Delay time.Duration
+}
+
+func (e *AcceptError) Error() string {
+	return fmt.Sprintf("accepting connection: %v; retrying in %v", e.Err, e.Delay)
+}
+
+func (e *AcceptError) Unwrap() error {
+	return e.Err
+}
+
+// isTransientError reports whether err, returned by Transport.Receive, only
+// affects a single packet, or connection, so that the next packet can be
+// received.
+func isTransientError(err error) bool {
+	var decodeErr *DecodeError
+	var truncated *TruncatedError
+	var acceptErr *AcceptError
+	// Connected UDP sockets report ICMP port unreachable of previously sent
+	// packets as connection refused.
+	return errors.As(err, &decodeErr) || errors.As(err, &truncated) ||
+		errors.As(err, &acceptErr) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// splitAddress splits an address with an optional network scheme, e.g.
+// "tcp://127.0.0.1:1234", into the network and the address. Addresses without
+// a scheme use the "udp" network.
+func splitAddress(address string) (network, addr string) {
+	if i := strings.Index(address, "://"); i >= 0 {
+		return address[:i], address[i+3:]
+	}
+	return "udp", address
+}
+
+// resolveRemote resolves the remote address of a client for the network of
+// address.
+func resolveRemote(address string) (net.Addr, error) {
+	network, addr := splitAddress(address)
+	switch network {
+	case "udp":
+		return net.ResolveUDPAddr("udp", addr)
+	case "tcp", "tcp+slip", "tls", "tls+slip":
+		return net.ResolveTCPAddr("tcp", addr)
+	case "unixgram":
+		return net.ResolveUnixAddr("unixgram", addr)
+	}
+	return nil, fmt.Errorf("unsupported network %q", network)
+}
+
+// dialTransport returns the client Transport for address and the remote
+// address to send to.
+func dialTransport(address string, opts *ClientOptions) (Transport, net.Addr, error) {
+	remote, err := resolveRemote(address)
+	if err != nil {
+		return nil, nil, err
+	}
+	var trans Transport
+	network, addr := splitAddress(address)
+	switch network {
+	case "udp":
+		ip := remote.(*net.UDPAddr).IP
+		switch {
+		case isBroadcastIP(ip):
+			trans, err = listenBroadcast(addr, opts.LocalAddress, opts.BufferSize)
+		case ip.IsMulticast():
+			trans, err = dialMulticast(addr, opts.LocalAddress, opts.BufferSize, opts.Multicast)
+		default:
+			trans, err = dialUDP(addr, opts.LocalAddress, opts.BufferSize)
+		}
+	case "tcp", "tcp+slip":
+		trans, err = NewTCPTransport(addr, &TCPOptions{
+			Framing:       streamFraming(network),
+			MaxPacketSize: opts.MaxPacketSize,
+			LocalAddress:  opts.LocalAddress,
+		})
+	case "tls", "tls+slip":
+		config := opts.TLSConfig
+		if config == nil {
+			config = &tls.Config{}
+		}
+		trans, err = NewTCPTransport(addr, &TCPOptions{
+			Framing:       streamFraming(network),
+			MaxPacketSize: opts.MaxPacketSize,
+			LocalAddress:  opts.LocalAddress,
+			TLSConfig:     config,
+		})
+	case "unixgram":
+		trans, err = dialUnixgram(addr, opts.LocalAddress, opts.BufferSize)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return trans, remote, nil
+}
+
+// streamFraming returns the Framing used by a stream network, e.g. FramingSLIP
+// for "tcp+slip".
+func streamFraming(network string) Framing {
+	if strings.HasSuffix(network, "+slip") {
+		return FramingSLIP
+	}
+	return FramingSizePrefix
+}
@@ -3,8 +3,10 @@ package gosc
 import (
 	"bufio"
 	"encoding/binary"
+	"image/color"
 	"io"
 	"reflect"
+	"strings"
 )
 
 type valueReader func(r *bufio.Reader) (any, error)
@@ -17,20 +19,20 @@ const (
 	TypeTagInt32           = typeTag('i')
 	TypeTagFloat32         = typeTag('f')
 	TypeTagString          = typeTag('s')
-	TypeTagBlob            = typeTag('b') // TODO: Implement write for TypeTagBlob
-	TypeTagBigInt          = typeTag('h') // TODO: Implement read/write for TypeTagBigInt
+	TypeTagBlob            = typeTag('b')
+	TypeTagBigInt          = typeTag('h')
 	TypeTagTimetag         = typeTag('t')
-	TypeTagDouble          = typeTag('d') // TODO: Implement read/write for TypeTagDouble
-	TypeTagStringAlternate = typeTag('S') // TODO: Implement read/write for TypeTagStringAlternate
-	TypeTagChar            = typeTag('c') // TODO: Implement read/write for TypeTagChar
-	TypeTagRGBA            = typeTag('r') // TODO: Implement read/write for TypeTagRGBA
-	TypeTagMIDI            = typeTag('m') // TODO: Implement read/write for TypeTagMIDI
-	TypeTagTrue            = typeTag('T') // TODO: Implement read/write for TypeTagTrue
-	TypeTagFalse           = typeTag('F') // TODO: Implement read/write for TypeTagFalse
-	TypeTagNil             = typeTag('N') // TODO: Implement read/write for TypeTagNil
-	TypeTagInfinite        = typeTag('I') // TODO: Implement read/write for TypeTagInfinite
-	TypeTagArrayStart      = typeTag('[') // TODO: Implement read/write for TypeTagArrayStart
-	TypeTagArrayStop       = typeTag(']') // TODO: Implement read/write for TypeTagArrayStop
+	TypeTagDouble          = typeTag('d')
+	TypeTagStringAlternate = typeTag('S')
+	TypeTagChar            = typeTag('c')
+	TypeTagRGBA            = typeTag('r')
+	TypeTagMIDI            = typeTag('m')
+	TypeTagTrue            = typeTag('T')
+	TypeTagFalse           = typeTag('F')
+	TypeTagNil             = typeTag('N')
+	TypeTagInfinite        = typeTag('I')
+	TypeTagArrayStart      = typeTag('[')
+	TypeTagArrayStop       = typeTag(']')
 )
 
 // readerMap is used to map typeTag to the correct reader function.
@@ -40,6 +42,17 @@ var readerMap = map[typeTag]valueReader{
 	TypeTagString:  stringReader,
 	TypeTagBlob:    blobReader,
 	TypeTagTimetag: timeTagReader,
+	TypeTagTrue:    trueReader,
+	TypeTagFalse:   falseReader,
+
+	TypeTagBigInt:          int64Reader,
+	TypeTagDouble:          float64Reader,
+	TypeTagStringAlternate: symbolReader,
+	TypeTagChar:            charReader,
+	TypeTagRGBA:            rgbaReader,
+	TypeTagMIDI:            midiReader,
+	TypeTagNil:             nilReader,
+	TypeTagInfinite:        infinitumReader,
 }
 
 // writeMap is used to map the golang types to the correct writer.
@@ -47,13 +60,36 @@ var writerMap = map[reflect.Type]valueWriter{
 	reflect.TypeOf(int32(0)):   int32Writer,
 	reflect.TypeOf(float32(0)): float32Writer,
 	reflect.TypeOf(""):         stringWriter,
+	reflect.TypeOf([]byte{}):   blobWriter,
 	reflect.TypeOf(Timetag(0)): timeTagWriter,
+	reflect.TypeOf(false):      boolWriter,
+
+	reflect.TypeOf(int64(0)):     int64Writer,
+	reflect.TypeOf(float64(0)):   float64Writer,
+	reflect.TypeOf(Symbol("")):   symbolWriter,
+	reflect.TypeOf(Char(0)):      charWriter,
+	reflect.TypeOf(color.RGBA{}): rgbaWriter,
+	reflect.TypeOf(MIDI{}):       midiWriter,
+	reflect.TypeOf(nil):          nilWriter,
+	reflect.TypeOf(Infinitum{}):  infinitumWriter,
 }
 
 func stringWriter(w *bufio.Writer, data any) (typeTag, error) {
 	return TypeTagString, writePaddedString(w, data.(string))
 }
 
+func blobWriter(w *bufio.Writer, data any) (typeTag, error) {
+	blob := data.([]byte)
+	if err := binary.Write(w, binary.BigEndian, int32(len(blob))); err != nil {
+		return TypeTagBlob, err
+	}
+	if _, err := w.Write(blob); err != nil {
+		return TypeTagBlob, err
+	}
+	_, err := w.Write(make([]byte, getPadBytes(len(blob))))
+	return TypeTagBlob, err
+}
+
 func float32Writer(w *bufio.Writer, data any) (typeTag, error) {
 	return TypeTagFloat32, binary.Write(w, binary.BigEndian, data)
 }
@@ -66,16 +102,55 @@ func timeTagWriter(w *bufio.Writer, data any) (typeTag, error) {
 	return TypeTagTimetag, binary.Write(w, binary.BigEndian, data)
 }
 
-func blobReader(r *bufio.Reader) (any, error) {
-	n := int32(0)
-	if err := binary.Read(r, binary.BigEndian, &n); err != nil {
-		return nil, err
+func boolWriter(_ *bufio.Writer, data any) (typeTag, error) {
+	if data.(bool) {
+		return TypeTagTrue, nil
 	}
-	res := make([]byte, n)
-	if _, err := io.ReadFull(r, res); err != nil {
+	return TypeTagFalse, nil
+}
+
+func int64Writer(w *bufio.Writer, data any) (typeTag, error) {
+	return TypeTagBigInt, binary.Write(w, binary.BigEndian, data)
+}
+
+func float64Writer(w *bufio.Writer, data any) (typeTag, error) {
+	return TypeTagDouble, binary.Write(w, binary.BigEndian, data)
+}
+
+func symbolWriter(w *bufio.Writer, data any) (typeTag, error) {
+	return TypeTagStringAlternate, writePaddedString(w, string(data.(Symbol)))
+}
+
+func charWriter(w *bufio.Writer, data any) (typeTag, error) {
+	return TypeTagChar, binary.Write(w, binary.BigEndian, int32(data.(Char)))
+}
+
+func rgbaWriter(w *bufio.Writer, data any) (typeTag, error) {
+	c := data.(color.RGBA)
+	_, err := w.Write([]byte{c.R, c.G, c.B, c.A})
+	return TypeTagRGBA, err
+}
+
+func midiWriter(w *bufio.Writer, data any) (typeTag, error) {
+	m := data.(MIDI)
+	_, err := w.Write([]byte{m.Port, m.Status, m.Data1, m.Data2})
+	return TypeTagMIDI, err
+}
+
+func nilWriter(_ *bufio.Writer, _ any) (typeTag, error) {
+	return TypeTagNil, nil
+}
+
+func infinitumWriter(_ *bufio.Writer, _ any) (typeTag, error) {
+	return TypeTagInfinite, nil
+}
+
+func blobReader(r *bufio.Reader) (any, error) {
+	res, err := readSized(r)
+	if err != nil {
 		return nil, err
 	}
-	_, err := r.Discard(getPadBytes(len(res) + 4))
+	_, err = r.Discard(getPadBytes(len(res) + 4))
 	if err != nil {
 		return nil, err
 	}
@@ -102,6 +177,67 @@ func int32Reader(r *bufio.Reader) (any, error) {
 	return res, nil
 }
 
+func trueReader(_ *bufio.Reader) (any, error) {
+	return true, nil
+}
+
+func falseReader(_ *bufio.Reader) (any, error) {
+	return false, nil
+}
+
+func int64Reader(r *bufio.Reader) (any, error) {
+	res := int64(0)
+	if err := binary.Read(r, binary.BigEndian, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func float64Reader(r *bufio.Reader) (any, error) {
+	res := float64(0)
+	if err := binary.Read(r, binary.BigEndian, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func symbolReader(r *bufio.Reader) (any, error) {
+	str, err := readPaddedString(r)
+	return Symbol(str), err
+}
+
+func charReader(r *bufio.Reader) (any, error) {
+	res := int32(0)
+	if err := binary.Read(r, binary.BigEndian, &res); err != nil {
+		return nil, err
+	}
+	return Char(res), nil
+}
+
+func rgbaReader(r *bufio.Reader) (any, error) {
+	b := make([]byte, 4)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return color.RGBA{R: b[0], G: b[1], B: b[2], A: b[3]}, nil
+}
+
+func midiReader(r *bufio.Reader) (any, error) {
+	b := make([]byte, 4)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return MIDI{Port: b[0], Status: b[1], Data1: b[2], Data2: b[3]}, nil
+}
+
+func nilReader(_ *bufio.Reader) (any, error) {
+	return nil, nil
+}
+
+func infinitumReader(_ *bufio.Reader) (any, error) {
+	return Infinitum{}, nil
+}
+
 func timeTagReader(r *bufio.Reader) (any, error) {
 	tt := Timetag(0)
 	if err := binary.Read(r, binary.BigEndian, &tt); err != nil {
@@ -109,3 +245,48 @@ func timeTagReader(r *bufio.Reader) (any, error) {
 	}
 	return tt, nil
 }
+
+// typeTagMap maps the Go types of arguments to their typeTag. Bools are not
+// included since their typeTag depends on the value.
+var typeTagMap = map[reflect.Type]typeTag{
+	reflect.TypeOf(int32(0)):     TypeTagInt32,
+	reflect.TypeOf(float32(0)):   TypeTagFloat32,
+	reflect.TypeOf(""):           TypeTagString,
+	reflect.TypeOf([]byte{}):     TypeTagBlob,
+	reflect.TypeOf(Timetag(0)):   TypeTagTimetag,
+	reflect.TypeOf(int64(0)):     TypeTagBigInt,
+	reflect.TypeOf(float64(0)):   TypeTagDouble,
+	reflect.TypeOf(Symbol("")):   TypeTagStringAlternate,
+	reflect.TypeOf(Char(0)):      TypeTagChar,
+	reflect.TypeOf(color.RGBA{}): TypeTagRGBA,
+	reflect.TypeOf(MIDI{}):       TypeTagMIDI,
+	reflect.TypeOf(nil):          TypeTagNil,
+	reflect.TypeOf(Infinitum{}):  TypeTagInfinite,
+}
+
+// typeTagOf returns the typeTag corresponding to the Go type of arg. The
+// boolean is false if the type has no OSC representation.
+func typeTagOf(arg any) (typeTag, bool) {
+	if b, ok := arg.(bool); ok {
+		if b {
+			return TypeTagTrue, true
+		}
+		return TypeTagFalse, true
+	}
+	tt, ok := typeTagMap[reflect.TypeOf(arg)]
+	return tt, ok
+}
+
+// writeTypeTags writes the type tags of args to sb, as they are written by
+// writeArguments. Arguments without an OSC representation are skipped.
+func writeTypeTags(sb *strings.Builder, args []any) {
+	for _, a := range args {
+		if arr, ok := a.([]any); ok {
+			sb.WriteByte(byte(TypeTagArrayStart))
+			writeTypeTags(sb, arr)
+			sb.WriteByte(byte(TypeTagArrayStop))
+		} else if tt, ok := typeTagOf(a); ok {
+			sb.WriteByte(byte(tt))
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package gosc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrorAddress is the address used by TypedHandler when replying with an error
+// to the sender.
+const ErrorAddress = "/error"
+
+// ErrSignatureMismatch is returned when the arguments of a Message do not match
+// the parameters of a TypedHandler function.
+var ErrSignatureMismatch = errors.New("signature mismatch")
+
+var (
+	responseWriterType = reflect.TypeOf((*ResponseWriter)(nil))
+	errorType          = reflect.TypeOf((*error)(nil)).Elem()
+	anyType            = reflect.TypeOf((*any)(nil)).Elem()
+	arrayType          = reflect.TypeOf([]any(nil))
+	intType            = reflect.TypeOf(0)
+)
+
+// TypedHandler is a MessageHandler calling an ordinary function with the
+// arguments of the Message bound to the parameters of the function. A
+// TypedHandler is created using NewTypedHandler.
+type TypedHandler struct {
+	fn       reflect.Value
+	params   []reflect.Type
+	variadic bool
+	// ErrorHandler is called when the Message does not match the parameters of
+	// the function or when the function returns an error. If nil, the error is
+	// sent back to the sender as a Message on ErrorAddress with the original
+	// address and the error string as arguments.
+	ErrorHandler func(w *ResponseWriter, msg *Message, err error)
+}
+
+// NewTypedHandler returns a TypedHandler calling fn. The first parameter of fn
+// must be a *ResponseWriter followed by one parameter for each argument of
+// the expected Message, e.g.
+//
+//	func(w *ResponseWriter, note int32, velocity float32) error
+//
+// Parameters must be one of the Go types the arguments are decoded as, int,
+// []any for arrays or any, which accepts every argument. Numbers are
+// converted when no precision is lost: int32 arguments bind to int, int64 and
+// float64 parameters, int64 arguments to int if they fit and float32
+// arguments to float64. A variadic last parameter accepts the remaining
+// arguments. The function may return nothing or an error.
+func NewTypedHandler(fn any) (*TypedHandler, error) {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil, fmt.Errorf("handler must be a function, got %T", fn)
+	}
+	t := v.Type()
+	if t.NumIn() == 0 || t.In(0) != responseWriterType {
+		return nil, errors.New("first handler parameter must be *ResponseWriter")
+	}
+	if t.NumOut() > 1 || (t.NumOut() == 1 && t.Out(0) != errorType) {
+		return nil, errors.New("handler must return nothing or an error")
+	}
+
+	params := make([]reflect.Type, 0, t.NumIn()-1)
+	for i := 1; i < t.NumIn(); i++ {
+		p := t.In(i)
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			p = p.Elem()
+		}
+		if !isBindable(p) {
+			return nil, fmt.Errorf("handler parameter %d has unsupported type %v", i, p)
+		}
+		params = append(params, p)
+	}
+
+	return &TypedHandler{
+		fn:       v,
+		params:   params,
+		variadic: t.IsVariadic(),
+	}, nil
+}
+
+// HandleMessage binds the arguments of msg to the parameters of the function
+// and calls it. Errors are passed to the ErrorHandler.
+func (h *TypedHandler) HandleMessage(w *ResponseWriter, msg *Message) {
+	in, err := h.bind(w, msg)
+	if err == nil {
+		err = h.call(in)
+	}
+	if err != nil {
+		h.handleError(w, msg, err)
+	}
+}
+
+func (h *TypedHandler) bind(w *ResponseWriter, msg *Message) ([]reflect.Value, error) {
+	n := len(h.params)
+	if len(msg.Arguments) < n-1 || (!h.variadic && len(msg.Arguments) != n) {
+		return nil, h.mismatch(msg)
+	}
+
+	in := make([]reflect.Value, 0, len(msg.Arguments)+1)
+	in = append(in, reflect.ValueOf(w))
+	for i, arg := range msg.Arguments {
+		p := h.params[n-1]
+		if i < n-1 || !h.variadic {
+			p = h.params[i]
+		}
+		if arg == nil {
+			if p != anyType {
+				return nil, h.mismatch(msg)
+			}
+			in = append(in, reflect.Zero(p))
+			continue
+		}
+		v, ok := convertArgument(arg, p)
+		if !ok {
+			return nil, h.mismatch(msg)
+		}
+		in = append(in, v)
+	}
+	return in, nil
+}
+
+// convertArgument returns arg as a value of type p, converting numbers
+// without loss of precision. It returns false if arg can not be converted.
+func convertArgument(arg any, p reflect.Type) (reflect.Value, bool) {
+	v := reflect.ValueOf(arg)
+	if v.Type().AssignableTo(p) {
+		return v, true
+	}
+	v = reflect.New(p).Elem()
+	switch a := arg.(type) {
+	case int32:
+		switch p.Kind() {
+		case reflect.Int, reflect.Int64:
+			v.SetInt(int64(a))
+		case reflect.Float64:
+			v.SetFloat(float64(a))
+		default:
+			return v, false
+		}
+	case int64:
+		if p != intType || v.OverflowInt(a) {
+			return v, false
+		}
+		v.SetInt(a)
+	case float32:
+		if p.Kind() != reflect.Float64 {
+			return v, false
+		}
+		v.SetFloat(float64(a))
+	default:
+		return v, false
+	}
+	return v, true
+}
+
+func (h *TypedHandler) call(in []reflect.Value) error {
+	out := h.fn.Call(in)
+	if len(out) == 1 && !out[0].IsNil() {
+		return out[0].Interface().(error)
+	}
+	return nil
+}
+
+func (h *TypedHandler) handleError(w *ResponseWriter, msg *Message, err error) {
+	if h.ErrorHandler != nil {
+		h.ErrorHandler(w, msg, err)
+		return
+	}
+	if w == nil {
+		return
+	}
+	_ = w.Send(&Message{
+		Address:   ErrorAddress,
+		Arguments: []any{msg.Address, err.Error()},
+	})
+}
+
+func (h *TypedHandler) mismatch(msg *Message) error {
+	return fmt.Errorf("%w: %s has arguments %s but handler expects %s", ErrSignatureMismatch,
+		msg.Address, msg.TypeTags(), h.signature())
+}
+
+// signature returns the type tag string expected by the handler. Parameters
+// of type any are shown as '*', arrays as "[]", booleans as "T|F", int as
+// "i|h" and a variadic parameter is followed by "...".
+func (h *TypedHandler) signature() string {
+	sb := strings.Builder{}
+	sb.WriteByte(',')
+	for _, p := range h.params {
+		if p == anyType {
+			sb.WriteByte('*')
+		} else if p == arrayType {
+			sb.WriteString("[]")
+		} else if p.Kind() == reflect.Bool {
+			sb.WriteString("T|F")
+		} else if p == intType {
+			sb.WriteString("i|h")
+		} else {
+			tt, _ := typeTagOf(reflect.Zero(p).Interface())
+			sb.WriteByte(byte(tt))
+		}
+	}
+	if h.variadic {
+		sb.WriteString("...")
+	}
+	return sb.String()
+}
+
+func isBindable(t reflect.Type) bool {
+	if t == anyType || t == arrayType || t == intType {
+		return true
+	}
+	_, ok := typeTagOf(reflect.Zero(t).Interface())
+	return ok
+}
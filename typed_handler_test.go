@@ -0,0 +1,156 @@
+package gosc
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+type testTransport struct {
+	sent []Package
+}
+
+func (t *testTransport) Send(pack Package, _ net.Addr) error {
+	t.sent = append(t.sent, pack)
+	return nil
+}
+
+func (t *testTransport) Receive() (Package, net.Addr, error) {
+	return nil, nil, errors.New("not implemented")
+}
+
+func TestNewTypedHandler(t *testing.T) {
+	t.Run("validFunction", func(t *testing.T) {
+		_, err := NewTypedHandler(func(w *ResponseWriter, note int32, vel float32) error { return nil })
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+	t.Run("variadic", func(t *testing.T) {
+		_, err := NewTypedHandler(func(w *ResponseWriter, name string, rest ...any) {})
+		if err != nil {
+			t.Errorf("expected no error but got: %v", err)
+		}
+	})
+	t.Run("notFunction", func(t *testing.T) {
+		_, err := NewTypedHandler("test")
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("missingResponseWriter", func(t *testing.T) {
+		_, err := NewTypedHandler(func(note int32) {})
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("unsupportedParameter", func(t *testing.T) {
+		_, err := NewTypedHandler(func(w *ResponseWriter, note uint16) {})
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("wrongReturn", func(t *testing.T) {
+		_, err := NewTypedHandler(func(w *ResponseWriter) int { return 0 })
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+}
+
+func TestTypedHandler_HandleMessage(t *testing.T) {
+	t.Run("boundArguments", func(t *testing.T) {
+		var gotNote int32
+		var gotVel float32
+		h, _ := NewTypedHandler(func(w *ResponseWriter, note int32, vel float32) {
+			gotNote, gotVel = note, vel
+		})
+		h.HandleMessage(nil, &Message{Address: "/synth/note", Arguments: []any{int32(60), float32(0.5)}})
+		if gotNote != 60 || gotVel != 0.5 {
+			t.Errorf("expected arguments 60 and 0.5 but got: %d and %f", gotNote, gotVel)
+		}
+	})
+	t.Run("variadicArguments", func(t *testing.T) {
+		var got []any
+		h, _ := NewTypedHandler(func(w *ResponseWriter, name string, rest ...any) {
+			got = rest
+		})
+		h.HandleMessage(nil, &Message{Address: "/test", Arguments: []any{"a", int32(1), "b"}})
+		if len(got) != 2 {
+			t.Errorf("expected 2 variadic arguments but got: %d", len(got))
+		}
+	})
+	t.Run("mismatchReplies", func(t *testing.T) {
+		called := false
+		h, _ := NewTypedHandler(func(w *ResponseWriter, note int32) {
+			called = true
+		})
+		trans := &testTransport{}
+		h.HandleMessage(NewResponseWriter(trans, nil), &Message{Address: "/test", Arguments: []any{"a"}})
+		if called {
+			t.Error("expected handler function not to be called")
+		}
+		if len(trans.sent) != 1 {
+			t.Fatalf("expected 1 error reply but got: %d", len(trans.sent))
+		}
+		if msg := trans.sent[0].(*Message); msg.Address != ErrorAddress {
+			t.Errorf("expected reply on %s but got: %s", ErrorAddress, msg.Address)
+		}
+	})
+	t.Run("wrongArgumentCount", func(t *testing.T) {
+		var got error
+		h, _ := NewTypedHandler(func(w *ResponseWriter, note int32) {})
+		h.ErrorHandler = func(_ *ResponseWriter, _ *Message, err error) {
+			got = err
+		}
+		h.HandleMessage(nil, &Message{Address: "/test", Arguments: []any{}})
+		if !errors.Is(got, ErrSignatureMismatch) {
+			t.Errorf("expected ErrSignatureMismatch but got: %v", got)
+		}
+	})
+	t.Run("returnedError", func(t *testing.T) {
+		var got error
+		h, _ := NewTypedHandler(func(w *ResponseWriter) error {
+			return errors.New("failed")
+		})
+		h.ErrorHandler = func(_ *ResponseWriter, _ *Message, err error) {
+			got = err
+		}
+		h.HandleMessage(nil, &Message{Address: "/test", Arguments: []any{}})
+		if got == nil || got.Error() != "failed" {
+			t.Errorf("expected returned error to be reported but got: %v", got)
+		}
+	})
+}
+
+func TestTypedHandler_HandleMessage_conversion(t *testing.T) {
+	t.Run("lossless", func(t *testing.T) {
+		var gotNote int
+		var gotTime int64
+		var gotVel float64
+		h, _ := NewTypedHandler(func(w *ResponseWriter, note int, time int64, vel float64) {
+			gotNote, gotTime, gotVel = note, time, vel
+		})
+		h.HandleMessage(nil, &Message{Address: "/synth/note", Arguments: []any{int32(60), int32(7), float32(0.5)}})
+		if gotNote != 60 || gotTime != 7 || gotVel != 0.5 {
+			t.Errorf("expected arguments 60, 7 and 0.5 but got: %d, %d and %f", gotNote, gotTime, gotVel)
+		}
+		h.HandleMessage(nil, &Message{Address: "/synth/note", Arguments: []any{int64(61), int32(8), int32(1)}})
+		if gotNote != 61 || gotTime != 8 || gotVel != 1 {
+			t.Errorf("expected arguments 61, 8 and 1 but got: %d, %d and %f", gotNote, gotTime, gotVel)
+		}
+	})
+	t.Run("lossyRejected", func(t *testing.T) {
+		for _, args := range [][]any{{int64(1)}, {float64(0.5)}, {"a"}} {
+			var got error
+			h, _ := NewTypedHandler(func(w *ResponseWriter, v float32) {})
+			h.ErrorHandler = func(_ *ResponseWriter, _ *Message, err error) {
+				got = err
+			}
+			h.HandleMessage(nil, &Message{Address: "/test", Arguments: args})
+			if !errors.Is(got, ErrSignatureMismatch) {
+				t.Errorf("expected ErrSignatureMismatch for %v but got: %v", args, got)
+			}
+		}
+	})
+}
@@ -1,7 +1,6 @@
 package gosc
 
 import (
-	"fmt"
 	"time"
 )
 
@@ -24,6 +23,12 @@ type Package interface {
 }
 
 // Message is the data structure for OSC message packets.
+//
+// Arguments are written with the type tag matching their Go type: int32 (i),
+// float32 (f), string (s), []byte (b), int64 (h), Timetag (t), float64 (d),
+// Symbol (S), Char (c), color.RGBA (r), MIDI (m), bool (T or F), nil (N),
+// Infinitum (I) and []any for arrays. Received arguments are decoded to the
+// same types.
 type Message struct {
 	// The Address is a '/' separated string as per the specification
 	Address string
@@ -37,10 +42,24 @@ func (m *Message) GetType() PackageType {
 	return PackageTypeMessage
 }
 
-func (m *Message) String() string {
-	return fmt.Sprintf("%s: %v", m.Address, m.Arguments)
+// Symbol is a string argument sent with the alternate string type tag 'S',
+// used by some systems to tell symbols apart from strings.
+type Symbol string
+
+// Char is a single ASCII character argument, sent with the type tag 'c'.
+type Char rune
+
+// MIDI is a MIDI message argument, sent with the type tag 'm'.
+type MIDI struct {
+	Port   byte
+	Status byte
+	Data1  byte
+	Data2  byte
 }
 
+// Infinitum is the argument sent with the type tag 'I'. It carries no data.
+type Infinitum struct{}
+
 // Timetag represents the time since 1900-01-01 00:00.
 type Timetag uint64
 
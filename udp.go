@@ -1,24 +1,30 @@
 package gosc
 
 import (
-	"bufio"
-	"bytes"
-	"errors"
 	"net"
 )
 
-type transportUDP struct {
-	conn       net.PacketConn
-	bufferSize int
-}
-
 // NewUDPTransport returns the default UDP Transport for clients.
 func NewUDPTransport(address string, bufferSize int) (Transport, error) {
-	conn, err := net.Dial("udp", address)
+	return dialUDP(address, "", bufferSize)
+}
+
+// dialUDP returns a UDP Transport connected to address and bound to the local
+// address, if not empty.
+func dialUDP(address, localAddress string, bufferSize int) (Transport, error) {
+	laddr, err := resolveLocalUDPAddr(localAddress)
 	if err != nil {
 		return nil, err
 	}
-	return &transportUDP{
+	dialer := net.Dialer{}
+	if laddr != nil {
+		dialer.LocalAddr = laddr
+	}
+	conn, err := dialer.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+	return &transportPacket{
 		conn:       conn.(net.PacketConn),
 		bufferSize: bufferSize,
 	}, nil
@@ -30,41 +36,17 @@ func NewUDPListen(address string, bufferSize int) (Transport, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &transportUDP{
+	return &transportPacket{
 		conn:       conn,
 		bufferSize: bufferSize,
 	}, nil
 }
 
-// Send uses buffering to send a complete Package on the UDP socket.
-func (t *transportUDP) Send(pack Package, addr net.Addr) error {
-	buf := bytes.Buffer{}
-	w := bufio.NewWriter(&buf)
-
-	err := writePackage(pack, w)
-	if err != nil {
-		return err
-	}
-	_ = w.Flush()
-	_, err = t.conn.WriteTo(buf.Bytes(), addr)
-	if errors.Is(err, net.ErrWriteToConnected) {
-		conn := t.conn.(*net.UDPConn)
-		_, err = conn.Write(buf.Bytes())
-	}
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-// Receive reads the UDP socket buffered and returns a Package when found.
-func (t *transportUDP) Receive() (pack Package, from net.Addr, err error) {
-	buf := make([]byte, t.bufferSize)
-	_, from, err = t.conn.ReadFrom(buf)
-	if err != nil {
-		return nil, from, err
+// resolveLocalUDPAddr resolves the local address of a client, nil is returned
+// for an empty address to use an ephemeral port.
+func resolveLocalUDPAddr(localAddress string) (*net.UDPAddr, error) {
+	if localAddress == "" {
+		return nil, nil
 	}
-	r := bufio.NewReaderSize(bytes.NewReader(buf), len(buf))
-	pack, err = readPackage(r)
-	return pack, from, err
+	return net.ResolveUDPAddr("udp", localAddress)
 }
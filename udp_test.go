@@ -1,6 +1,9 @@
 package gosc
 
 import (
+	"bytes"
+	"errors"
+	"net"
 	"testing"
 )
 
@@ -34,10 +37,84 @@ func TestNewUDPListen(t *testing.T) {
 	})
 }
 
-func Test_transportUDP_Receive(t1 *testing.T) {
-	// TODO: Implement me
+func Test_transportPacket_Receive(t *testing.T) {
+	listen, err := NewUDPListen("127.0.0.1:0", 64)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer listen.(*transportPacket).Close()
+	cli, err := NewUDPTransport(listen.(*transportPacket).conn.LocalAddr().String(), 0)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer cli.(*transportPacket).Close()
+
+	t.Run("bundle", func(t *testing.T) {
+		err := cli.Send(&Bundle{
+			Timetag:  Immediately,
+			Messages: []*Message{{Address: "/a", Arguments: []any{int32(1)}}},
+		}, nil)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		pkg, _, err := listen.Receive()
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if b := pkg.(*Bundle); len(b.Messages) != 1 || b.Messages[0].Address != "/a" {
+			t.Errorf("expected bundle with message /a but got: %v", pkg)
+		}
+	})
+	t.Run("truncated", func(t *testing.T) {
+		_ = cli.Send(&Message{Address: "/a", Arguments: []any{make([]byte, 64)}}, nil)
+		_, _, err := listen.Receive()
+		var truncated *TruncatedError
+		if !errors.As(err, &truncated) || truncated.BufferSize != 64 {
+			t.Errorf("expected TruncatedError but got: %v", err)
+		}
+	})
+	t.Run("reusedBuffer", func(t *testing.T) {
+		_ = cli.Send(&Message{Address: "/a", Arguments: []any{[]byte{1, 2, 3}}}, nil)
+		_ = cli.Send(&Message{Address: "/b", Arguments: []any{[]byte{4, 5, 6}}}, nil)
+		first, _, err := listen.Receive()
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		_, _, _ = listen.Receive()
+		if b, _ := first.(*Message).Blob(0); first.(*Message).Address != "/a" || !bytes.Equal(b, []byte{1, 2, 3}) {
+			t.Errorf("expected first package to be unchanged but got: %v", first)
+		}
+	})
+	t.Run("malformed", func(t *testing.T) {
+		_, _ = cli.(*transportPacket).conn.(net.Conn).Write([]byte("/a\x00\x00,b\x00\x00\xff\xff\xff\xff"))
+		_, _, err := listen.Receive()
+		var decodeErr *DecodeError
+		if !errors.As(err, &decodeErr) {
+			t.Errorf("expected DecodeError but got: %v", err)
+		}
+	})
+	t.Run("exactSize", func(t *testing.T) {
+		_ = cli.Send(&Message{Address: "/a", Arguments: []any{make([]byte, 52)}}, nil)
+		pkg, _, err := listen.Receive()
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if b, _ := pkg.(*Message).Blob(0); len(b) != 52 {
+			t.Errorf("expected blob of 52 bytes but got: %v", pkg)
+		}
+	})
 }
 
-func Test_transportUDP_Send(t1 *testing.T) {
-	// TODO: Implement me
+func Test_setSocketBuffers(t *testing.T) {
+	trans, err := NewUDPListen("127.0.0.1:0", 0)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	defer trans.(*transportPacket).Close()
+	if err := setSocketBuffers(trans, 1<<16, 1<<16); err != nil {
+		t.Errorf("expected no error but got: %v", err)
+	}
+	if err := setSocketBuffers(&testTransport{}, 1<<16, 1<<16); err != nil {
+		t.Errorf("expected no error for other transports but got: %v", err)
+	}
 }
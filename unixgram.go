@@ -0,0 +1,65 @@
+package gosc
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// NewUnixgramTransport returns a Transport for clients sending to the unix
+// datagram socket at address. The client is bound to a socket file in a new
+// temporary directory so the server can reply, it is removed on Close.
+func NewUnixgramTransport(address string, bufferSize int) (Transport, error) {
+	return dialUnixgram(address, "", bufferSize)
+}
+
+// dialUnixgram returns a unixgram Transport bound to the socket file at
+// localAddress, or a temporary one if empty. The socket file is removed on
+// Close.
+func dialUnixgram(address, localAddress string, bufferSize int) (Transport, error) {
+	raddr, err := net.ResolveUnixAddr("unixgram", address)
+	if err != nil {
+		return nil, err
+	}
+	remove := localAddress
+	if localAddress == "" {
+		dir, err := os.MkdirTemp("", "gosc")
+		if err != nil {
+			return nil, err
+		}
+		localAddress = filepath.Join(dir, "client.sock")
+		remove = dir
+	}
+	laddr := &net.UnixAddr{Name: localAddress, Net: "unixgram"}
+	conn, err := net.DialUnix("unixgram", laddr, raddr)
+	if err != nil {
+		if remove != localAddress {
+			_ = os.RemoveAll(remove)
+		}
+		return nil, err
+	}
+	return &transportPacket{
+		conn:          conn,
+		bufferSize:    bufferSize,
+		removeOnClose: remove,
+	}, nil
+}
+
+// NewUnixgramListen returns a Transport for servers listening on the unix
+// datagram socket at address. The socket file must not exist and is removed
+// on Close. Access can be restricted with the permissions of the file.
+func NewUnixgramListen(address string, bufferSize int) (Transport, error) {
+	laddr, err := net.ResolveUnixAddr("unixgram", address)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUnixgram("unixgram", laddr)
+	if err != nil {
+		return nil, err
+	}
+	return &transportPacket{
+		conn:          conn,
+		bufferSize:    bufferSize,
+		removeOnClose: address,
+	}, nil
+}
@@ -0,0 +1,63 @@
+package gosc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewUnixgramListen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "osc.sock")
+	trans, err := NewUnixgramListen(path, 512)
+	if err != nil {
+		t.Fatalf("expected no error but got: %v", err)
+	}
+	if _, err := NewUnixgramListen(path, 512); err == nil {
+		t.Error("expected error for existing socket but none given")
+	}
+	_ = trans.(*transportPacket).Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected socket file to be removed but got: %v", err)
+	}
+}
+
+func TestNewUnixgramTransport(t *testing.T) {
+	t.Run("missingServer", func(t *testing.T) {
+		_, err := NewUnixgramTransport(filepath.Join(t.TempDir(), "osc.sock"), 512)
+		if err == nil {
+			t.Error("expected error but none given")
+		}
+	})
+	t.Run("reply", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "osc.sock")
+		mux := NewMux(nil)
+		mux.HandleMessageFunc("/hello", func(w *ResponseWriter, msg *Message) {
+			_ = w.Send(&Message{Address: msg.Address, Arguments: []any{"World"}})
+		})
+		trans, err := NewUnixgramListen(path, 512)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		defer trans.(*transportPacket).Close()
+		go func() {
+			_ = NewServer(&ServerOptions{}).Serve(trans, mux)
+		}()
+
+		cli, err := NewClient("unixgram://" + path)
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		local := cli.transport.(*transportPacket).removeOnClose
+		res, err := cli.CallMessage("/hello")
+		if err != nil {
+			t.Fatalf("expected no error but got: %v", err)
+		}
+		if s, _ := res.StringArg(0); s != "World" {
+			t.Errorf("expected response \"World\" but got: %v", res)
+		}
+		_ = cli.Close()
+		if _, err := os.Stat(local); !os.IsNotExist(err) {
+			t.Errorf("expected client socket to be removed but got: %v", err)
+		}
+	})
+}
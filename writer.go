@@ -9,6 +9,17 @@ import (
 	"strings"
 )
 
+// encodePackage returns the encoded Package.
+func encodePackage(pack Package) ([]byte, error) {
+	buf := bytes.Buffer{}
+	w := bufio.NewWriter(&buf)
+	if err := writePackage(pack, w); err != nil {
+		return nil, err
+	}
+	_ = w.Flush()
+	return buf.Bytes(), nil
+}
+
 func writePackage(pack Package, w *bufio.Writer) error {
 	switch v := pack.(type) {
 	case *Message:
@@ -76,15 +87,8 @@ func writeArguments(w *bufio.Writer, arguments []any) error {
 	_, _ = ttString.WriteRune(',')
 
 	for _, a := range arguments {
-		typ := reflect.TypeOf(a)
-		if writer, ok := writerMap[typ]; ok {
-			tt, err := writer(bufWriter, a)
-			if err != nil {
-				return err
-			}
-			if err := ttString.WriteByte(byte(tt)); err != nil {
-				return err
-			}
+		if err := writeArgument(bufWriter, &ttString, a); err != nil {
+			return err
 		}
 	}
 
@@ -100,6 +104,31 @@ func writeArguments(w *bufio.Writer, arguments []any) error {
 	return nil
 }
 
+// writeArgument writes the data of a single argument to w and its typeTag to
+// ttString. Arrays are written recursively between TypeTagArrayStart and
+// TypeTagArrayStop. Arguments without a writer are skipped.
+func writeArgument(w *bufio.Writer, ttString *strings.Builder, a any) error {
+	if arr, ok := a.([]any); ok {
+		ttString.WriteByte(byte(TypeTagArrayStart))
+		for _, e := range arr {
+			if err := writeArgument(w, ttString, e); err != nil {
+				return err
+			}
+		}
+		ttString.WriteByte(byte(TypeTagArrayStop))
+		return nil
+	}
+	writer, ok := writerMap[reflect.TypeOf(a)]
+	if !ok {
+		return nil
+	}
+	tt, err := writer(w, a)
+	if err != nil {
+		return err
+	}
+	return ttString.WriteByte(byte(tt))
+}
+
 func writePaddedString(w *bufio.Writer, str string) error {
 	if !strings.HasSuffix(str, "\x00") {
 		str += "\x00"
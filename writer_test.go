@@ -79,3 +79,24 @@ func Test_writePaddedString(t *testing.T) {
 func Test_writePayload(t *testing.T) {
 	// TODO: Implement me
 }
+
+func Test_blobWriter(t *testing.T) {
+	buf := bytes.Buffer{}
+	r := bufio.NewReader(&buf)
+	w := bufio.NewWriter(&buf)
+	_, err := blobWriter(w, []byte{1, 2, 3, 4, 5})
+	if err != nil {
+		t.Errorf("expected no error but got: %v", err)
+	}
+	_ = w.Flush()
+	if buf.Len() != 12 {
+		t.Errorf("blob was %d bytes but expected 12", buf.Len())
+	}
+	res, err := blobReader(r)
+	if err != nil {
+		t.Errorf("expected no error but got: %v", err)
+	}
+	if !bytes.Equal(res.([]byte), []byte{1, 2, 3, 4, 5}) {
+		t.Errorf("expected blob to be read back but got: %v", res)
+	}
+}